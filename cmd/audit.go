@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/cmd/ssh"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+)
+
+// parseAuditTime accepts RFC3339, a plain date, or a relative age like 24h or 7d.
+// A plain date means the start of that day, or its last moment with endOfDay,
+// so that --until 2026-10-17 includes the whole of the 17th.
+func parseAuditTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339, YYYY-MM-DD, or an age like 24h or 7d", value)
+}
+
+func formatAuditEntry(e credential.AuditEntry) string {
+	line := fmt.Sprintf("%s  %-8s %-12s %s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Action, e.User, e.Credential)
+	if e.Host != "" {
+		line += fmt.Sprintf(" (%s)", e.Host)
+	}
+	if len(e.Fields) > 0 {
+		line += fmt.Sprintf(" fields=%s", strings.Join(e.Fields, ","))
+	}
+	if e.StartedAt != nil && e.EndedAt != nil {
+		line += fmt.Sprintf(" duration=%s", e.EndedAt.Sub(*e.StartedAt).Round(time.Second))
+	}
+	if e.ExitCode != nil {
+		line += fmt.Sprintf(" exit=%d", *e.ExitCode)
+	}
+	if e.Recording != "" {
+		line += fmt.Sprintf(" recording=%s", e.Recording)
+	}
+
+	return line
+}
+
+func newAuditCmd() *cobra.Command {
+	var (
+		name    string
+		action  string
+		since   string
+		until   string
+		jsonOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:          "audit",
+		Short:        "Show the audit log of credential operations and connections",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := credential.AuditFilter{
+				Credential: strings.ToLower(strings.TrimSpace(name)),
+				Action:     credential.AuditAction(action),
+			}
+
+			var err error
+			if filter.Since, err = parseAuditTime(since, false); err != nil {
+				return err
+			}
+			if filter.Until, err = parseAuditTime(until, true); err != nil {
+				return err
+			}
+
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			entries, err := store.ReadAuditLog(filter)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if jsonOut {
+				enc := json.NewEncoder(out)
+				for _, e := range entries {
+					if err := enc.Encode(e); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			if len(entries) == 0 {
+				fmt.Fprintln(out, "No audit entries found")
+				return nil
+			}
+			for _, e := range entries {
+				fmt.Fprintln(out, formatAuditEntry(e))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "credential", "c", "", "Only show entries for this credential name or ID")
+	cmd.Flags().StringVarP(&action, "action", "a", "", "Only show this action (save/update/delete/restore/purge/connect)")
+	cmd.Flags().StringVar(&since, "since", "", "Only show entries after this time (RFC3339, YYYY-MM-DD, or age like 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only show entries before this time (RFC3339, YYYY-MM-DD, or age like 7d)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print entries as JSON lines")
+	_ = cmd.RegisterFlagCompletionFunc("credential", ssh.CompleteCredentialFlag)
+
+	return cmd
+}
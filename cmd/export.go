@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/cmd/ssh"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/ansible"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+)
+
+func newExportAnsibleCmd() *cobra.Command {
+	var (
+		format string
+		output string
+		list   bool
+		host   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ansible",
+		Short: "Write the saved hosts as an Ansible inventory",
+		Long: "Write the saved hosts as an Ansible inventory with ansible_host, ansible_port, ansible_user and\n" +
+			"ansible_ssh_private_key_file set. Tags become groups: env=prod puts a host in env_prod.\n" +
+			"Passwords are never written.\n\n" +
+			"With --list or --host, ssh-cli behaves as a dynamic inventory script. Point Ansible at a\n" +
+			"small wrapper such as:\n\n" +
+			"  #!/bin/sh\n  exec ssh-cli export ansible \"$@\"\n\n" +
+			"To go the other way, use 'ssh-cli store import --from ansible <inventory>'.",
+		Example: "  ssh-cli export ansible -o inventory.ini\n  ssh-cli export ansible --format yaml\n" +
+			"  ansible-inventory -i ./ssh-cli-inventory --graph",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list && host != "" {
+				return errors.New("use only one of --list and --host")
+			}
+
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+			inv := ansible.FromCredentials(store.ListCredentials())
+
+			var buf bytes.Buffer
+			switch {
+			case list:
+				err = inv.WriteList(&buf)
+			case host != "":
+				err = inv.WriteHost(&buf, host)
+			default:
+				err = inv.Write(&buf, strings.ToLower(format))
+			}
+			if err != nil {
+				return err
+			}
+
+			if output == "" || output == "-" {
+				_, err := cmd.OutOrStdout().Write(buf.Bytes())
+				return err
+			}
+			if err := os.WriteFile(ssh.ExpandHome(output), buf.Bytes(), 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d hosts to %s\n", len(inv.Hosts), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", ansible.FormatINI, "Inventory format (ini/yaml)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+	cmd.Flags().BoolVar(&list, "list", false, "Print all groups and hosts as dynamic inventory JSON")
+	cmd.Flags().StringVar(&host, "host", "", "Print the variables of one host as dynamic inventory JSON")
+	_ = cmd.RegisterFlagCompletionFunc("host", ssh.CompleteCredentialFlag)
+
+	return cmd
+}
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export saved hosts for use by other tools",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newExportAnsibleCmd())
+
+	return cmd
+}
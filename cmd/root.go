@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/hemupadhyay26/ssh-cred-manager-cli/cmd/ssh"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
 	"github.com/spf13/cobra"
 )
 
@@ -14,18 +16,57 @@ func newRootCmd(version string) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if allow, _ := cmd.Flags().GetBool(ssh.InsecurePermissionsFlag); allow {
+				credential.StrictPermissions = false
+			}
+			credential.TrashRetention = ssh.TrashRetention
+			if !checksStore(cmd) {
+				return nil
+			}
+			cmd.SilenceUsage = true
+			return ssh.CheckStorePermissions()
+		},
 	}
+	cmd.PersistentFlags().Bool(ssh.InsecurePermissionsFlag, false, "Only warn about a store or key file that other users can read")
 
 	cmd.AddCommand(newVersionCmd(version)) // version subcommand
 	cmd.AddCommand(ssh.NewSSHCmd())
+	cmd.AddCommand(newAuditCmd())
+	cmd.AddCommand(newUICmd())
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newSessionsCmd())
+	cmd.AddCommand(newSyncCmd())
+	cmd.AddCommand(newStoreCmd())
+	cmd.AddCommand(newExportCmd())
 	// Register the man command
 	cmd.AddCommand(NewManCmd().Cmd)
 
 	return cmd
 }
 
+// checksStore reports whether a command needs the store's permissions checked
+// first. Commands that never read credentials skip it, and so does the doctor,
+// which reports and repairs permissions itself.
+func checksStore(cmd *cobra.Command) bool {
+	if !cmd.HasParent() {
+		return false
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, "completion", "help", "version", "man", "config", "doctor":
+			return false
+		}
+	}
+	return true
+}
+
 // Execute invokes the command.
 func Execute(version string) error {
+	if handled, err := ssh.HandleAskpass(os.Args[1:]); handled {
+		return err
+	}
+
 	if err := newRootCmd(version).Execute(); err != nil {
 		return fmt.Errorf("error executing root command: %w", err)
 	}
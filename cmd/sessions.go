@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/session"
+	"github.com/spf13/cobra"
+)
+
+func newSessionsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List recorded sessions, newest first",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			infos, err := session.List(store.SessionDir())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if len(infos) == 0 {
+				fmt.Fprintln(out, "No recorded sessions found")
+				return nil
+			}
+			for _, info := range infos {
+				fmt.Fprintf(out, "%s  %-40s %8s %8dB  %s\n",
+					info.Started.Local().Format("2006-01-02 15:04:05"), info.Name,
+					info.Duration.Round(time.Second), info.Size, info.Title)
+			}
+			return nil
+		},
+	}
+}
+
+// completeSessionNames completes the name of a recording, reading the store
+// without writing to it
+func completeSessionNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	store, err := credential.ReadCredentialStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	infos, err := session.List(store.SessionDir())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, info := range infos {
+		if strings.HasPrefix(info.Name, toComplete) {
+			names = append(names, info.Name+"\t"+info.Title)
+		}
+	}
+	// A recording can also be given as a file
+	if len(names) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func newSessionsPlayCmd() *cobra.Command {
+	var (
+		speed   float64
+		maxIdle time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:     "play <name or file>",
+		Short:   "Replay a recorded session in the terminal",
+		Example: "  ssh-cli sessions play prod-20240101-120000 --speed 2 --max-idle 2s",
+		Args:    cobra.ExactArgs(1),
+
+		ValidArgsFunction: completeSessionNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if speed <= 0 {
+				return fmt.Errorf("--speed must be greater than 0")
+			}
+
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			path, err := session.Find(store.SessionDir(), args[0])
+			if err != nil {
+				return err
+			}
+			cast, err := session.Read(path)
+			if err != nil {
+				return err
+			}
+
+			if err := session.Play(os.Stdout, cast, speed, maxIdle); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "\nEnd of recording %s\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().Float64Var(&speed, "speed", 1, "Playback speed factor")
+	cmd.Flags().DurationVar(&maxIdle, "max-idle", 0, "Shorten pauses longer than this (e.g. 2s)")
+
+	return cmd
+}
+
+func newSessionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "sessions",
+		Short:        "List and replay recorded SSH sessions",
+		Long:         "List and replay SSH sessions recorded with 'ssh-cli ssh connect --record'.\nRecordings are asciicast v2 files in ~/.ssh-cred-manager/sessions and can also be played with asciinema.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newSessionsListCmd())
+	cmd.AddCommand(newSessionsPlayCmd())
+
+	return cmd
+}
@@ -0,0 +1,177 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// adHocOptions are the connect flags for targets without a saved credential
+type adHocOptions struct {
+	key  string
+	save bool
+	name string
+}
+
+func (o adHocOptions) given() bool {
+	return o.key != "" || o.save || o.name != ""
+}
+
+// isAdHocTarget reports whether a connect argument is meant as a connection
+// string rather than a credential name
+func isAdHocTarget(value string) bool {
+	return strings.Contains(value, "@") || strings.Contains(value, "://")
+}
+
+// connectAdHoc connects to a target that has no saved credential. ssh records
+// the host key in a known_hosts file used for this session only, so that when
+// the target is saved afterwards its key is already pinned.
+func connectAdHoc(store *credential.CredentialStore, value string, opts adHocOptions, extraArgs []string, record bool) error {
+	target, err := credential.ParseTarget(value, defaults().User, defaults().Port)
+	if err != nil {
+		return err
+	}
+	if target.User == "" {
+		return fmt.Errorf("no user given for %s and no default user configured: use user@host", value)
+	}
+
+	keyPath := ExpandHome(opts.key)
+	if keyPath != "" {
+		if _, err := os.Stat(keyPath); err != nil {
+			return fmt.Errorf("SSH key file not found: %s", keyPath)
+		}
+	}
+
+	knownHosts, err := os.CreateTemp("", "ssh-cli-known-hosts-*")
+	if err != nil {
+		return err
+	}
+	knownHosts.Close()
+	defer os.Remove(knownHosts.Name())
+
+	cred := credential.SSHCredential{
+		Name:     target.String(),
+		Host:     target.Host,
+		Port:     target.Port,
+		Username: target.User,
+		AuthType: credential.KeyFile,
+		KeyPath:  keyPath,
+		SSHOptions: map[string]string{
+			"UserKnownHostsFile":    knownHosts.Name(),
+			"StrictHostKeyChecking": "accept-new",
+			"HashKnownHosts":        "no",
+		},
+	}
+	if err := checkKeyPermissions(&cred); err != nil {
+		return err
+	}
+	exitCode, runErr := runSession(store, &cred, extraArgs, record)
+	// 255 is ssh's own failure, such as an unreachable host or a rejected login
+	if exitCode < 0 || exitCode == 255 {
+		return runErr
+	}
+
+	if !opts.save {
+		if !stdinIsTerminal() {
+			return runErr
+		}
+		answer := promptForInput(fmt.Sprintf("Save %s as a credential? [y/N]", target))
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return runErr
+		}
+	}
+	if err := saveAdHoc(store, cred, opts.name, knownHosts.Name()); err != nil {
+		if runErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return runErr
+		}
+		return err
+	}
+	return runErr
+}
+
+// saveAdHoc saves a target after a session, pinning the host keys ssh
+// recorded in knownHosts
+func saveAdHoc(store *credential.CredentialStore, cred credential.SSHCredential, name, knownHosts string) error {
+	defaultName := strings.ToLower(cred.Username + "@" + cred.Host)
+	if name == "" && stdinIsTerminal() {
+		name = promptForInput(fmt.Sprintf("Enter connection name (default: %s)", defaultName))
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = defaultName
+	}
+	if existing, _ := store.GetCredential(name); existing != nil {
+		if !stdinIsTerminal() {
+			return fmt.Errorf("a credential named %s already exists: pass another --name", name)
+		}
+		newName, err := promptForNewName(store, name)
+		if err != nil {
+			return err
+		}
+		name = strings.ToLower(strings.TrimSpace(newName))
+	}
+
+	id, err := credential.GenerateID()
+	if err != nil {
+		return fmt.Errorf("failed to generate unique ID: %w", err)
+	}
+	now := time.Now()
+	cred.ID = id
+	cred.Name = name
+	cred.SSHOptions = nil
+	cred.CreatedAt = now
+	cred.UpdatedAt = now
+	if cred.KeyPath == "" && stdinIsTerminal() {
+		if err := promptAdHocAuth(&cred); err != nil {
+			return err
+		}
+	}
+
+	pinned, err := store.PinHostKeys(&cred, knownHosts)
+	if err != nil {
+		return fmt.Errorf("failed to pin host key: %w", err)
+	}
+	if pinned == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: ssh recorded no host key for %s, so none was pinned\n", cred.Host)
+	}
+
+	if err := store.SaveCredential(cred); err != nil {
+		return fmt.Errorf("failed to save credential: %w", err)
+	}
+	if err := store.RecordUse(cred.Name); err != nil {
+		return fmt.Errorf("failed to record connection history: %w", err)
+	}
+	fmt.Printf("Successfully saved SSH credential for %s (%d host keys pinned)\n", name, pinned)
+	return nil
+}
+
+// promptAdHocAuth asks how a target connected to without --key logs in. By
+// default no key is recorded, so ssh keeps using its agent and config the way
+// it did for the session.
+func promptAdHocAuth(cred *credential.SSHCredential) error {
+	fmt.Println("Authentication type: agent (ssh agent and config, as in this session), key or password")
+	switch auth := strings.ToLower(promptForInput("Enter auth type (default: agent)")); auth {
+	case "", "agent":
+	case "key":
+		defaultKey := DefaultKeyPath()
+		keyPath := ExpandHome(promptForInput(fmt.Sprintf("Enter key path (default: %s)", defaultKey)))
+		if keyPath == "" {
+			keyPath = defaultKey
+		}
+		if _, err := os.Stat(keyPath); err != nil {
+			return fmt.Errorf("SSH key file not found: %s", keyPath)
+		}
+		cred.KeyPath = keyPath
+		return checkKey(cred)
+	case "password":
+		cred.AuthType = credential.Password
+		cred.Password = promptForPassword("Enter password")
+	default:
+		return fmt.Errorf("invalid authentication type %q: use agent, key or password", auth)
+	}
+	return nil
+}
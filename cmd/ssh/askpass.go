@@ -0,0 +1,88 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+const (
+	// askpassNonceEnv tells a copy of ssh-cli started by ssh as SSH_ASKPASS
+	// which secret file belongs to the current session
+	askpassNonceEnv = "SSH_CLI_ASKPASS"
+	// askpassPromptEnv is the word a prompt must contain to be answered
+	askpassPromptEnv = "SSH_CLI_ASKPASS_PROMPT"
+)
+
+// askpassEnv returns the environment that makes ssh ask this binary for a secret
+// instead of prompting, for prompts containing the given word ("password" or
+// "passphrase"). It needs OpenSSH 8.4 or newer for SSH_ASKPASS_REQUIRE.
+//
+// Everything ssh starts inherits this environment, so it only carries a
+// random nonce naming a file under the store directory. The file is removed
+// as soon as the askpass helper has read it, or by cleanup once the session
+// is over.
+func askpassEnv(secret, prompt string) ([]string, func(), error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := credential.GenerateID()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, cleanup, err := credential.WritePrivateFile(askpassFileName(nonce), secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env := []string{
+		"SSH_ASKPASS=" + exe,
+		"SSH_ASKPASS_REQUIRE=force",
+		askpassNonceEnv + "=" + nonce,
+		askpassPromptEnv + "=" + prompt,
+	}
+	return env, cleanup, nil
+}
+
+func askpassFileName(nonce string) string {
+	return "askpass-" + nonce
+}
+
+// HandleAskpass answers ssh's password or passphrase prompt when this binary was
+// started as SSH_ASKPASS by Connect. It reports whether it handled the
+// invocation: ssh passes the prompt as the only argument, and the nonce must
+// name a secret file that has not been read yet.
+func HandleAskpass(args []string) (bool, error) {
+	nonce := os.Getenv(askpassNonceEnv)
+	if nonce == "" || len(args) != 1 || strings.Trim(nonce, "0123456789abcdef") != "" {
+		return false, nil
+	}
+	path, err := credential.PrivateFilePath(askpassFileName(nonce))
+	if err != nil {
+		return false, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return false, nil
+	}
+
+	// Only answer the expected prompt; anything else, like host key confirmations, is declined
+	prompt := os.Getenv(askpassPromptEnv)
+	if prompt == "" {
+		prompt = "password"
+	}
+	if !strings.Contains(strings.ToLower(strings.Join(args, " ")), prompt) {
+		return true, errors.New("ssh-cli only answers " + prompt + " prompts")
+	}
+
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return true, err
+	}
+	os.Remove(path)
+	_, err = fmt.Println(string(secret))
+	return true, err
+}
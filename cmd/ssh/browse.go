@@ -0,0 +1,102 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/tui"
+	"golang.org/x/term"
+)
+
+// BrowseOptions controls a session of the credential UI
+type BrowseOptions struct {
+	// Until makes the UI a picker for this action, closing it once the action
+	// has been carried out; ActionQuit keeps it open until the user quits
+	Until tui.Action
+	// Enter is what the Enter key does; connecting by default
+	Enter tui.Action
+	// Status is shown in the footer until the first key press
+	Status string
+}
+
+// interactiveTerminal reports whether both stdin and stdout are a terminal,
+// which the UI needs
+func interactiveTerminal() bool {
+	return stdinIsTerminal() && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func waitForEnter(reader *bufio.Reader) {
+	fmt.Print("\nPress Enter to return to the credential list...")
+	reader.ReadString('\n')
+}
+
+// Browse runs the credential UI on the terminal. Connecting, editing and
+// deleting chosen in it are carried out with the terminal back in normal
+// mode, after which the UI returns to the list.
+func Browse(store *credential.CredentialStore, opts BrowseOptions) error {
+	tty, err := tui.NewTTY(os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	model := tui.NewModel(credential.SortCredentials(store.ListCredentials(), credential.SortName))
+	if opts.Enter != tui.ActionQuit {
+		model.SetEnterAction(opts.Enter)
+	}
+	model.SetOnlyAction(opts.Until)
+	model.SetStatus(opts.Status)
+
+	for {
+		if err := tty.Raw(); err != nil {
+			return fmt.Errorf("failed to enter raw mode: %w", err)
+		}
+		result, err := tui.Run(tty, model)
+		tty.Restore()
+		if err != nil {
+			return err
+		}
+
+		switch result.Action {
+		case tui.ActionQuit:
+			return nil
+		case tui.ActionConnect:
+			cred := result.Credentials[0]
+			if err := Connect(store, &cred, nil, false); err != nil {
+				fmt.Fprintf(os.Stderr, "Connection to %s ended: %v\n", cred.Name, err)
+				waitForEnter(reader)
+			}
+		case tui.ActionEdit:
+			cred := result.Credentials[0]
+			if err := EditCredential(reader, store, &cred, cred.Name); err != nil {
+				if opts.Until == tui.ActionEdit {
+					return err
+				}
+				fmt.Fprintln(os.Stderr, err)
+				waitForEnter(reader)
+			} else {
+				model.SetStatus(fmt.Sprintf("Updated %s", cred.Name))
+				if opts.Until == tui.ActionEdit {
+					fmt.Println("Credential updated successfully.")
+				}
+			}
+		case tui.ActionDelete:
+			for _, cred := range result.Credentials {
+				if err := store.DeleteCredential(cred.Name); err != nil {
+					return fmt.Errorf("failed to delete credential %s: %w", cred.Name, err)
+				}
+			}
+			model.SetStatus(fmt.Sprintf("Moved %d credential(s) to trash", len(result.Credentials)))
+			if opts.Until == tui.ActionDelete {
+				fmt.Printf("Moved %d credential(s) to trash. Restore with: ssh-cli ssh trash restore <name>\n", len(result.Credentials))
+			}
+		}
+		if opts.Until != tui.ActionQuit && result.Action == opts.Until {
+			return nil
+		}
+
+		model.SetCredentials(credential.SortCredentials(store.ListCredentials(), credential.SortName))
+	}
+}
@@ -0,0 +1,158 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// loadCAKey reads a CA private key, asking for its passphrase if it is encrypted
+func loadCAKey(path string) (gossh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA key: %w", err)
+	}
+
+	signer, err := gossh.ParsePrivateKey(data)
+	var missing *gossh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		passphrase := promptForPassword(fmt.Sprintf("Enter passphrase for CA key %s", path))
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA key: %w", err)
+	}
+	return signer, nil
+}
+
+func newCertSignCmd() *cobra.Command {
+	var (
+		caPath     string
+		keyPath    string
+		principals []string
+		validity   time.Duration
+		keyID      string
+		serial     uint64
+		outPath    string
+		credName   string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "sign",
+		Short:   "Sign a user key with a local CA key",
+		Example: "  ssh-cli ssh cert sign --ca ~/.ssh/user_ca --credential prod --principals deploy --validity 8h",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var store *credential.CredentialStore
+			var cred *credential.SSHCredential
+			if credName != "" {
+				var err error
+				store, err = credential.NewCredentialStore()
+				if err != nil {
+					return fmt.Errorf("failed to initialize credential store: %w", err)
+				}
+				cred, err = store.FindCredential(strings.ToLower(strings.TrimSpace(credName)))
+				if err != nil {
+					return err
+				}
+				if keyPath == "" {
+					keyPath = cred.KeyPath
+				}
+				if len(principals) == 0 {
+					principals = []string{cred.Username}
+				}
+			}
+			if keyPath == "" {
+				return errors.New("a key to sign is required: use --key or --credential")
+			}
+
+			pub, pubPath, err := credential.LoadPublicKey(ExpandHome(keyPath))
+			if err != nil {
+				return err
+			}
+			ca, err := loadCAKey(ExpandHome(caPath))
+			if err != nil {
+				return err
+			}
+
+			if keyID == "" {
+				keyID = fmt.Sprintf("ssh-cli:%s", strings.Join(principals, ","))
+			}
+			if serial == 0 {
+				serial = uint64(time.Now().Unix())
+			}
+
+			certData, err := credential.SignUserKey(credential.SignOptions{
+				CA:         ca,
+				PublicKey:  pub,
+				KeyID:      keyID,
+				Principals: principals,
+				Validity:   validity,
+				Serial:     serial,
+			})
+			if err != nil {
+				return err
+			}
+
+			if outPath == "" {
+				outPath = credential.CertPathFor(pubPath)
+			}
+			outPath = ExpandHome(outPath)
+			if err := os.WriteFile(outPath, certData, 0644); err != nil {
+				return fmt.Errorf("failed to write certificate: %w", err)
+			}
+
+			// Report what the written certificate says, not what was asked for
+			cert, err := credential.LoadCertificate(outPath)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Signed certificate written to %s\n", outPath)
+			fmt.Printf("Principals: %s | Valid from: %s | Valid until: %s\n", strings.Join(cert.ValidPrincipals, ", "),
+				time.Unix(int64(cert.ValidAfter), 0).Format("2006-01-02 15:04"), credential.CertExpiry(cert).Format("2006-01-02 15:04"))
+
+			if cred != nil {
+				cred.CertPath = outPath
+				cred.UpdatedAt = time.Now()
+				if err := store.UpdateCredential(cred.Name, *cred); err != nil {
+					return fmt.Errorf("failed to update credential: %w", err)
+				}
+				fmt.Printf("Credential %s now uses this certificate\n", cred.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&caPath, "ca", "", "CA private key used for signing (required)")
+	cmd.Flags().StringVarP(&keyPath, "key", "k", "", "User public key (or private key with a .pub next to it) to sign")
+	cmd.Flags().StringSliceVar(&principals, "principals", nil, "Comma-separated user names the certificate is valid for (default: the credential's username)")
+	cmd.Flags().DurationVar(&validity, "validity", 8*time.Hour, "How long the certificate stays valid")
+	cmd.Flags().StringVar(&keyID, "id", "", "Key identity recorded in the certificate and in server logs")
+	cmd.Flags().Uint64Var(&serial, "serial", 0, "Certificate serial number (default: current Unix time)")
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "Where to write the certificate (default: <key>-cert.pub)")
+	cmd.Flags().StringVarP(&credName, "credential", "c", "", "Sign this credential's key and attach the certificate to it")
+	_ = cmd.RegisterFlagCompletionFunc("credential", CompleteCredentialFlag)
+	cmd.MarkFlagRequired("ca")
+
+	return cmd
+}
+
+func NewCertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cert",
+		Short: "Manage SSH user certificates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newCertSignCmd())
+
+	return cmd
+}
@@ -0,0 +1,136 @@
+package ssh
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+)
+
+// Completion reads the store with credential.ReadCredentialStore, so it never
+// prompts, writes or touches secrets. A store that cannot be read simply
+// offers nothing.
+
+// credentialCompletions returns the names of credentials, and their IDs when
+// the word being completed starts like one, described by user@host:port
+func credentialCompletions(creds []credential.SSHCredential, toComplete string) []string {
+	var completions []string
+	for _, cred := range creds {
+		target := credential.Target{User: cred.Username, Host: cred.Host, Port: cred.Port}.String()
+		if strings.HasPrefix(cred.Name, toComplete) {
+			completions = append(completions, cred.Name+"\t"+target)
+		}
+		if toComplete != "" && strings.HasPrefix(cred.ID, toComplete) {
+			completions = append(completions, cred.ID+"\t"+cred.Name+" "+target)
+		}
+	}
+	return completions
+}
+
+// CompleteCredentialNames completes the first argument with a saved
+// credential name or ID
+func CompleteCredentialNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return CompleteCredentialFlag(cmd, args, toComplete)
+}
+
+// CompleteCredentialFlag completes a flag value with a saved credential name
+// or ID
+func CompleteCredentialFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := credential.ReadCredentialStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return credentialCompletions(store.ListCredentials(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTrashNames completes the name of a deleted credential
+func completeTrashNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	store, err := credential.ReadCredentialStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, cred := range store.ListTrash() {
+		if !seen[cred.Name] && strings.HasPrefix(cred.Name, toComplete) {
+			seen[cred.Name] = true
+			names = append(names, cred.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// storeTags returns every tag value in use, by key
+func storeTags() (map[string][]string, error) {
+	store, err := credential.ReadCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	sets := make(map[string]map[string]bool)
+	for _, cred := range store.ListCredentials() {
+		for key, value := range cred.Tags {
+			if sets[key] == nil {
+				sets[key] = make(map[string]bool)
+			}
+			sets[key][value] = true
+		}
+	}
+	tags := make(map[string][]string, len(sets))
+	for key, values := range sets {
+		for value := range values {
+			tags[key] = append(tags[key], value)
+		}
+		sort.Strings(tags[key])
+	}
+	return tags, nil
+}
+
+// CompleteTags completes key=value tags: first the keys in use, then the
+// values already used with the chosen key
+func CompleteTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	tags, err := storeTags()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if key, prefix, ok := strings.Cut(toComplete, "="); ok {
+		var completions []string
+		for _, value := range tags[key] {
+			if value != "" && strings.HasPrefix(value, prefix) {
+				completions = append(completions, key+"="+value)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for key := range tags {
+		if strings.HasPrefix(key, toComplete) {
+			completions = append(completions, key+"=")
+		}
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeTagKeys completes the key of a tag in use
+func completeTagKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	tags, err := storeTags()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var keys []string
+	for key := range tags {
+		if strings.HasPrefix(key, toComplete) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
@@ -2,54 +2,275 @@ package ssh
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/session"
 	"github.com/spf13/cobra"
 )
 
+// askpassSecret returns the secret ssh will prompt for, if ssh-cli knows it: the
+// password for password auth, or the key passphrase when a reference is stored
+func askpassSecret(cred *credential.SSHCredential) (secret, prompt string, err error) {
+	switch {
+	case cred.AuthType == credential.Password:
+		secret, err = cred.ResolvePassword()
+		return secret, "password", err
+	case cred.KeyPassRef != "":
+		secret, err = credential.ResolveSecret(cred.KeyPassRef)
+		return secret, "passphrase", err
+	}
+	return "", "", nil
+}
+
+// buildSSHArgs assembles the ssh command line. Options are the configured
+// ones, overridden by the credential's own, and host keys are checked the way
+// ssh's own configuration says. The credential's extra arguments come before
+// the destination and the ones given for this run after it, where ssh
+// accepts both further options and a remote command. The credential's remote
+// command runs on a terminal unless this run brings its own command.
+func buildSSHArgs(cred *credential.SSHCredential, keyPath string, extraArgs []string) []string {
+	sshArgs := []string{"-p", strconv.Itoa(cred.Port)}
+	if cred.AuthType == credential.KeyFile && keyPath != "" {
+		sshArgs = append(sshArgs, "-i", keyPath)
+	}
+
+	options := credential.MergeSSHOptions(defaults().SSHOptions, cred.SSHOptions)
+	if cred.CertPath != "" {
+		options = credential.MergeSSHOptions(options, map[string]string{"CertificateFile": cred.CertPath})
+	}
+	sshArgs = append(sshArgs, credential.SSHOptionArgs(options)...)
+	sshArgs = append(sshArgs, cred.ExtraArgs...)
+
+	remoteCommand := cred.RemoteCommand != "" && !credential.HasRemoteCommand(extraArgs)
+	if remoteCommand {
+		sshArgs = append(sshArgs, "-t")
+	}
+
+	sshArgs = append(sshArgs, fmt.Sprintf("%s@%s", cred.Username, cred.Host))
+	sshArgs = append(sshArgs, extraArgs...)
+	if remoteCommand {
+		sshArgs = append(sshArgs, cred.RemoteCommand)
+	}
+	return sshArgs
+}
+
+// Connect runs an interactive ssh session for a credential, recording its use
+// in the connection history and the audit log. extraArgs are passed to ssh for
+// this session only. With record set the terminal output is also saved as an
+// asciicast file in the store's session directory.
+func Connect(store *credential.CredentialStore, cred *credential.SSHCredential, extraArgs []string, record bool) error {
+	if err := checkKeyPermissions(cred); err != nil {
+		return err
+	}
+	if err := store.RecordUse(cred.Name); err != nil {
+		return fmt.Errorf("failed to record connection history: %w", err)
+	}
+	_, err := runSession(store, cred, extraArgs, record)
+	return err
+}
+
+// runSession runs ssh for a credential and records the connection in the
+// audit log. It returns the exit code of ssh, or -1 if it did not run.
+func runSession(store *credential.CredentialStore, cred *credential.SSHCredential, extraArgs []string, record bool) (int, error) {
+	fmt.Printf("Connecting to %s...\n", credential.Target{User: cred.Username, Host: cred.Host, Port: cred.Port})
+
+	// Secrets held by reference are only resolved now, right before they are needed
+	keyPath, cleanupKey, err := cred.ResolveKeyPath()
+	if err != nil {
+		return -1, err
+	}
+	defer cleanupKey()
+
+	if warning := cred.CertWarning(); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	sshArgs := buildSSHArgs(cred, keyPath, extraArgs)
+
+	cmdExec := exec.Command(defaults().SSHBinary, sshArgs...)
+	secret, prompt, err := askpassSecret(cred)
+	if err != nil {
+		return -1, err
+	}
+	if secret != "" {
+		env, cleanupSecret, err := askpassEnv(secret, prompt)
+		if err != nil {
+			return -1, fmt.Errorf("failed to pass %s to ssh: %w", prompt, err)
+		}
+		defer cleanupSecret()
+		cmdExec.Env = append(os.Environ(), env...)
+	}
+	start := time.Now()
+	var runErr error
+	var recording string
+	if record {
+		recording, runErr = recordSession(store, cred, cmdExec, start)
+	} else {
+		cmdExec.Stdin = os.Stdin
+		cmdExec.Stdout = os.Stdout
+		cmdExec.Stderr = os.Stderr
+		runErr = cmdExec.Run()
+	}
+	exitCode := 0
+	if runErr != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	if err := store.AuditConnection(*cred, start, time.Now(), exitCode, recording); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	return exitCode, runErr
+}
+
+// recordSession runs ssh on a pseudo-terminal and saves its output. It returns
+// the path of the recording.
+func recordSession(store *credential.CredentialStore, cred *credential.SSHCredential, cmdExec *exec.Cmd, start time.Time) (string, error) {
+	path, err := session.NewPath(store.SessionDir(), cred.Name, start)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	width, height := session.TerminalSize()
+	rec, err := session.Create(path, session.Header{
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     fmt.Sprintf("%s (%s@%s:%d)", cred.Name, cred.Username, cred.Host, cred.Port),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start recording: %w", err)
+	}
+	fmt.Printf("Recording session to %s\n", path)
+
+	runErr := session.Run(cmdExec, rec)
+	if err := rec.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save recording: %v\n", err)
+	}
+	return path, runErr
+}
+
+// errCredentialNotFound is returned by findCredential when nothing matches
+var errCredentialNotFound = errors.New("credential not found")
+
+// findCredential looks a credential up by name or ID, or failing that by a
+// connection string such as deploy@web1:2222 that matches a single saved host
+func findCredential(store *credential.CredentialStore, name string) (*credential.SSHCredential, error) {
+	if cred, err := store.FindCredential(name); err == nil {
+		return cred, nil
+	}
+	target, err := credential.ParseTarget(name, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errCredentialNotFound, name)
+	}
+	matches := store.FindByTarget(target)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", errCredentialNotFound, name)
+	case 1:
+		return &matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, match := range matches {
+		names[i] = match.Name
+	}
+	return nil, fmt.Errorf("%s matches several credentials (%s): connect by name instead", name, strings.Join(names, ", "))
+}
+
 // NewConnectCmd returns a cobra command for connecting via SSH.
 func NewConnectCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:     "connect",
-		Short:   "Connect to an SSH server using a saved credential",
+	var (
+		last            bool
+		noRemoteCommand bool
+		record          bool
+		adHoc           adHocOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "connect [name|user@host[:port]] [-- ssh args or remote command...]",
+		Short: "Connect to an SSH server using a saved credential",
+		Long: "Connect to an SSH server using a saved credential, found by name or by user@host[:port].\n" +
+			"A connection string that matches no credential connects ad hoc. After the session ssh-cli offers\n" +
+			"to save it, with the host key seen during the session pinned in the store's known_hosts file.",
 		Aliases: []string{"c", "conn"},
+		Example: "  ssh-cli ssh connect prod -- -L 8080:localhost:80\n  ssh-cli ssh connect deploy@10.0.0.5:2222 --key ~/.ssh/deploy\n  ssh-cli ssh connect prod -- 'tail -f /var/log/app.log'",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				args = args[:dash]
+			}
+			return cobra.MaximumNArgs(1)(cmd, args)
+		},
+		ValidArgsFunction: CompleteCredentialNames,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			reader := bufio.NewReader(os.Stdin)
-			fmt.Print("Enter credential name: ")
-			name, _ := reader.ReadString('\n')
-			name = strings.TrimSpace(name)
+			var extraArgs []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				extraArgs = args[dash:]
+				args = args[:dash]
+			}
 
 			store, err := credential.NewCredentialStore()
 			if err != nil {
 				return fmt.Errorf("failed to open credential store: %w", err)
 			}
 
-			cred, err := store.GetCredential(name)
-			if err != nil {
-				return fmt.Errorf("credential not found: %w", err)
-			}
+			var cred *credential.SSHCredential
+			switch {
+			case last:
+				if len(args) > 0 || adHoc.given() {
+					return fmt.Errorf("--last cannot be combined with a credential name or target")
+				}
+				cred, err = store.LastUsedCredential()
+				if err != nil {
+					return err
+				}
+			default:
+				var name string
+				if len(args) > 0 {
+					name = strings.TrimSpace(args[0])
+				} else {
+					reader := bufio.NewReader(os.Stdin)
+					fmt.Print("Enter credential name: ")
+					name, _ = reader.ReadString('\n')
+					name = strings.TrimSpace(name)
+				}
 
-			fmt.Printf("Connecting to %s@%s:%d...\n", cred.Username, cred.Host, cred.Port)
-
-			sshArgs := []string{
-				"-p", strconv.Itoa(cred.Port),
-				"-i", cred.KeyPath,
-				fmt.Sprintf("%s@%s", cred.Username, cred.Host),
-				"-o", "StrictHostKeyChecking=no",
-				"-o", "UserKnownHostsFile=/dev/null",
+				cred, err = findCredential(store, name)
+				if errors.Is(err, errCredentialNotFound) && (isAdHocTarget(name) || adHoc.given()) {
+					return connectAdHoc(store, name, adHoc, extraArgs, record)
+				}
+				if err != nil {
+					return err
+				}
+				if adHoc.given() {
+					return fmt.Errorf("--key, --save and --name are for targets without a credential; %s is saved as %s", name, cred.Name)
+				}
 			}
 
-			cmdExec := exec.Command("ssh", sshArgs...)
-			cmdExec.Stdin = os.Stdin
-			cmdExec.Stdout = os.Stdout
-			cmdExec.Stderr = os.Stderr
-
-			return cmdExec.Run()
+			if noRemoteCommand {
+				cred.RemoteCommand = ""
+			}
+			return Connect(store, cred, extraArgs, record)
 		},
 	}
+
+	cmd.Flags().BoolVar(&last, "last", false, "Reconnect to the most recently used credential")
+	cmd.Flags().BoolVar(&record, "record", false, "Record the session to an asciicast file (see ssh-cli sessions)")
+	cmd.Flags().StringVarP(&adHoc.key, "key", "k", "", "SSH private key for a target without a credential")
+	cmd.Flags().BoolVar(&adHoc.save, "save", false, "Save a target without a credential after the session without asking")
+	cmd.Flags().StringVar(&adHoc.name, "name", "", "Name to save a target without a credential under")
+	cmd.Flags().BoolVar(&noRemoteCommand, "no-remote-command", false, "Open a plain shell instead of running the credential's remote command")
+
+	return cmd
 }
@@ -0,0 +1,136 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+)
+
+// warnDuplicates warns when the account a credential logs in to is already
+// saved under another name
+func warnDuplicates(store *credential.CredentialStore, cred credential.SSHCredential) {
+	duplicates := store.Duplicates(cred)
+	if len(duplicates) == 0 {
+		return
+	}
+	names := make([]string, len(duplicates))
+	for i, dup := range duplicates {
+		names[i] = dup.Name
+	}
+	target := credential.Target{User: cred.Username, Host: cred.Host, Port: cred.Port}
+	fmt.Fprintf(os.Stderr, "Warning: %s is already saved as %s (merge with 'ssh-cli ssh dedupe')\n", target, strings.Join(names, ", "))
+}
+
+func printDuplicateGroup(group []credential.SSHCredential, preferred int) {
+	for i, cred := range group {
+		mark := " "
+		if i == preferred {
+			mark = "*"
+		}
+		line := fmt.Sprintf(" %s [%d] %s  uses %d, last used %s, created %s", mark, i+1, cred.Name,
+			cred.UseCount, formatLastUsed(cred), cred.CreatedAt.Format("2006-01-02"))
+		if len(cred.Tags) > 0 {
+			line += ", tags " + credential.FormatTags(cred.Tags)
+		}
+		fmt.Println(line)
+	}
+}
+
+func NewDedupeCmd() *cobra.Command {
+	var (
+		resolve bool
+		yes     bool
+		dryRun  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find credentials for the same user, host and port and merge them",
+		Long: "Find credentials that log in to the same user, host and port and merge each group into one.\n" +
+			"Host names are compared case-insensitively; with --resolve they are also looked up, so a name\n" +
+			"and its IP address count as the same host. The merged credential keeps the tags, ssh options and\n" +
+			"usage history of the whole group; the others are moved to the trash.",
+		Example:      "  ssh-cli ssh dedupe --dry-run\n  ssh-cli ssh dedupe --resolve\n  ssh-cli ssh dedupe --yes",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !yes && !dryRun && !stdinIsTerminal() {
+				return fmt.Errorf("stdin is not a terminal: use --yes to keep the most used credential of each group, or --dry-run")
+			}
+
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			groups := credential.DuplicateGroups(store.ListCredentials(), resolve)
+			if len(groups) == 0 {
+				fmt.Println("No duplicate credentials found")
+				return nil
+			}
+
+			reader := bufio.NewReader(os.Stdin)
+			merged := 0
+			for i, group := range groups {
+				preferred := credential.PreferredDuplicate(group)
+				first := group[0]
+				fmt.Printf("\nDuplicates %d of %d: %s\n", i+1, len(groups), credential.Target{User: first.Username, Host: first.Host, Port: first.Port})
+				printDuplicateGroup(group, preferred)
+				if dryRun {
+					continue
+				}
+
+				keep := preferred
+				if !yes {
+					fmt.Printf("Keep which? [1-%d, Enter for %d, s to skip]: ", len(group), preferred+1)
+					answer, _ := reader.ReadString('\n')
+					answer = strings.TrimSpace(answer)
+					if strings.EqualFold(answer, "s") {
+						continue
+					}
+					if answer != "" {
+						n, err := strconv.Atoi(answer)
+						if err != nil || n < 1 || n > len(group) {
+							fmt.Println("Invalid choice, skipping this group")
+							continue
+						}
+						keep = n - 1
+					}
+				}
+
+				var others []credential.SSHCredential
+				var names []string
+				for j, cred := range group {
+					if j != keep {
+						others = append(others, cred)
+						names = append(names, cred.Name)
+					}
+				}
+				result := credential.MergeDuplicates(group[keep], others)
+				if err := store.MergeCredentials(group[keep].Name, result, names); err != nil {
+					return fmt.Errorf("failed to merge into %s: %w", group[keep].Name, err)
+				}
+				fmt.Printf("Merged %s into %s; moved them to the trash\n", strings.Join(names, ", "), group[keep].Name)
+				merged++
+			}
+
+			if dryRun {
+				fmt.Printf("\n%d groups of duplicates; * marks the credential --yes would keep\n", len(groups))
+				return nil
+			}
+			fmt.Printf("\nMerged %d of %d groups\n", merged, len(groups))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&resolve, "resolve", false, "Look up host names so names and IP addresses of the same host match")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Merge every group into its most used credential without asking")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the duplicates")
+
+	return cmd
+}
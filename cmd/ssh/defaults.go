@@ -0,0 +1,36 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/config"
+)
+
+var (
+	defaultsOnce sync.Once
+	userDefaults *config.Config
+)
+
+// defaults returns the effective user configuration. A broken config file is
+// reported once and the built-in defaults are used instead, so it never stops
+// credentials from being listed or used.
+func defaults() *config.Config {
+	defaultsOnce.Do(func() {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v (using built-in defaults)\n", err)
+			cfg = &config.Config{Port: 22, SSHBinary: "ssh", Output: config.OutputText, TrashRetentionDays: config.DefaultTrashRetentionDays}
+		}
+		userDefaults = cfg
+	})
+	return userDefaults
+}
+
+// TrashRetention returns how long deleted credentials are kept, from the
+// trash_retention_days setting
+func TrashRetention() time.Duration {
+	return time.Duration(defaults().TrashRetentionDays) * 24 * time.Hour
+}
@@ -0,0 +1,57 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// checkKey prints what kind of key a credential uses and, for an encrypted key,
+// verifies the passphrase when one is available. This catches bad keys at save
+// time instead of at connect time. A key that cannot be parsed here, such as a
+// FIDO key, may still work with ssh, so that is only a warning.
+func checkKey(cred *credential.SSHCredential) error {
+	if cred.AuthType != credential.KeyFile || cred.KeyRef != "" || cred.KeyPath == "" {
+		return nil
+	}
+	if err := checkKeyPermissions(cred); err != nil {
+		return err
+	}
+
+	info, err := credential.InspectKey(cred.KeyPath, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not inspect the key: %v\n", err)
+		return nil
+	}
+	fmt.Printf("Key: %s\n", info)
+	if !info.Encrypted {
+		return nil
+	}
+
+	var passphrase string
+	switch {
+	case cred.KeyPassRef != "":
+		passphrase, err = credential.ResolveSecret(cred.KeyPassRef)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not verify the key passphrase: %v\n", err)
+			return nil
+		}
+	case stdinIsTerminal():
+		passphrase = promptForPassword("Key is encrypted. Enter passphrase to verify it (leave blank to skip)")
+	}
+	if passphrase == "" {
+		return nil
+	}
+
+	if _, err := credential.InspectKey(cred.KeyPath, []byte(passphrase)); err != nil {
+		if errors.Is(err, credential.ErrWrongPassphrase) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: could not verify the key passphrase: %v\n", err)
+		return nil
+	}
+	fmt.Println("Key passphrase verified")
+	return nil
+}
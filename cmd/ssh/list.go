@@ -1,14 +1,36 @@
 package ssh
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
+	"io"
 	"strings"
 
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/config"
 	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
 	"github.com/spf13/cobra"
 )
 
+func formatLastUsed(cred credential.SSHCredential) string {
+	if cred.LastUsedAt == nil {
+		return "never"
+	}
+	return cred.LastUsedAt.Format("2006-01-02 15:04")
+}
+
+// printCredentialsJSON writes credentials as a JSON array. Stored passwords are
+// left out; references to where they live are kept.
+func printCredentialsJSON(w io.Writer, credentials []credential.SSHCredential) error {
+	redacted := make([]credential.SSHCredential, len(credentials))
+	for i, cred := range credentials {
+		cred.Password = ""
+		redacted[i] = cred
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(redacted)
+}
+
 func NewListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "list",
@@ -20,7 +42,26 @@ func NewListCmd() *cobra.Command {
 				return fmt.Errorf("failed to initialize credential store: %w", err)
 			}
 
-			credentials := store.ListCredentials()
+			sortFlag, _ := cmd.Flags().GetString("sort")
+			order, err := credential.ParseSortOrder(sortFlag)
+			if err != nil {
+				return err
+			}
+
+			credentials := credential.SortCredentials(store.ListCredentials(), order)
+
+			output, _ := cmd.Flags().GetString("output")
+			if !cmd.Flags().Changed("output") {
+				output = defaults().Output
+			}
+			switch output {
+			case config.OutputJSON:
+				return printCredentialsJSON(cmd.OutOrStdout(), credentials)
+			case config.OutputText:
+			default:
+				return fmt.Errorf("invalid output format %q: use text or json", output)
+			}
+
 			if len(credentials) == 0 {
 				fmt.Println("No SSH credentials found")
 				return nil
@@ -37,6 +78,27 @@ func NewListCmd() *cobra.Command {
 					fmt.Printf("    Host: %s:%d\n", cred.Host, cred.Port)
 					fmt.Printf("    Username: %s\n", cred.Username)
 					fmt.Printf("    Auth Type: %s\n", cred.AuthType)
+					if len(cred.Tags) > 0 {
+						fmt.Printf("    Tags: %s\n", credential.FormatTags(cred.Tags))
+					}
+					if len(cred.SSHOptions) > 0 {
+						fmt.Printf("    SSH Options: %s\n", credential.FormatTags(cred.SSHOptions))
+					}
+					if len(cred.ExtraArgs) > 0 {
+						fmt.Printf("    Extra Args: %s\n", strings.Join(cred.ExtraArgs, " "))
+					}
+					if cred.RemoteCommand != "" {
+						fmt.Printf("    Remote Command: %s\n", cred.RemoteCommand)
+					}
+					fmt.Printf("    Created: %s\n", cred.CreatedAt.Format("2006-01-02 15:04"))
+					fmt.Printf("    Updated: %s\n", cred.UpdatedAt.Format("2006-01-02 15:04"))
+					fmt.Printf("    Last Used: %s (%d uses)\n", formatLastUsed(cred), cred.UseCount)
+					if cred.CertPath != "" {
+						fmt.Printf("    Certificate: %s\n", cred.CertPath)
+					}
+					if warning := cred.CertWarning(); warning != "" {
+						fmt.Printf("    Warning: %s\n", warning)
+					}
 					fmt.Println("---------------------")
 				}
 				return nil
@@ -46,42 +108,19 @@ func NewListCmd() *cobra.Command {
 			fmt.Println("---------------------")
 			for i, cred := range credentials {
 				fmt.Printf("[%d] Name: %s | ID: %s\n", i+1, cred.Name, cred.ID)
-			}
-			fmt.Println("---------------------")
-			fmt.Print("Enter the numbers of the credentials you want to view (comma-separated, e.g. 1,3): ")
-			var input string
-			fmt.Scanln(&input)
-			input = strings.TrimSpace(input)
-			if input == "" {
-				fmt.Println("No selection made.")
-				return nil
-			}
-			selections := strings.Split(input, ",")
-			selected := make(map[int]bool)
-			for _, sel := range selections {
-				sel = strings.TrimSpace(sel)
-				if idx, err := strconv.Atoi(sel); err == nil && idx > 0 && idx <= len(credentials) {
-					selected[idx-1] = true
+				if warning := cred.CertWarning(); warning != "" {
+					fmt.Printf("    Warning: %s\n", warning)
 				}
 			}
-			fmt.Println("\nSelected SSH credentials:")
 			fmt.Println("---------------------")
-			for i, cred := range credentials {
-				if selected[i] {
-					fmt.Printf("Name: %s\n", cred.Name)
-					fmt.Printf("ID: %s\n", cred.ID)
-					fmt.Printf("Host: %s:%d\n", cred.Host, cred.Port)
-					fmt.Printf("Username: %s\n", cred.Username)
-					fmt.Printf("Auth Type: %s\n", cred.AuthType)
-					fmt.Println("---------------------")
-				}
-			}
 			return nil
 		},
 	}
 
 	// Add -l/--long flag for long output
 	cmd.Flags().BoolP("long", "l", false, "Show detailed output (long format)")
+	cmd.Flags().String("sort", string(credential.SortCreated), "Sort order (recent/frequent/name/created)")
+	cmd.Flags().String("output", "", "Output format (text/json, defaults to the configured output)")
 
 	return cmd
 }
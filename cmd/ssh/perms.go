@@ -0,0 +1,50 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// InsecurePermissionsFlag opts out of refusing files that other users can read
+const InsecurePermissionsFlag = "allow-insecure-permissions"
+
+// insecure refuses a file with a permission problem, or only warns about it
+// when the user opted out with the flag or the allow_insecure_permissions setting
+func insecure(err error) error {
+	var permErr *credential.PermissionError
+	if !errors.As(err, &permErr) {
+		return err
+	}
+	if !credential.StrictPermissions || defaults().AllowInsecurePermissions {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return fmt.Errorf("%w\nOnly you should be able to read it: run 'chmod go-rwx %s' (and chown it if needed), or pass --%s to continue anyway",
+		err, permErr.Path, InsecurePermissionsFlag)
+}
+
+// CheckStorePermissions refuses to open a store whose file or directory other
+// users can read, the way ssh refuses such a private key
+func CheckStorePermissions() error {
+	problems, err := credential.CheckStorePermissions()
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		if err := insecure(problem); err != nil {
+			return fmt.Errorf("%w\n'ssh-cli store doctor --fix' repairs the store's permissions", err)
+		}
+	}
+	return nil
+}
+
+// checkKeyPermissions checks a private key file before it is saved or used
+func checkKeyPermissions(cred *credential.SSHCredential) error {
+	if cred.AuthType != credential.KeyFile || cred.KeyRef != "" || cred.KeyPath == "" {
+		return nil
+	}
+	return insecure(credential.CheckPermissions(cred.KeyPath))
+}
@@ -0,0 +1,61 @@
+package ssh
+
+import (
+	"fmt"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+)
+
+func NewRecentCmd() *cobra.Command {
+	var (
+		limit    int
+		frequent bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "List recently used SSH credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			order := credential.SortRecent
+			if frequent {
+				order = credential.SortFrequent
+			}
+
+			var used []credential.SSHCredential
+			for _, cred := range credential.SortCredentials(store.ListCredentials(), order) {
+				if cred.LastUsedAt != nil {
+					used = append(used, cred)
+				}
+			}
+			if len(used) == 0 {
+				fmt.Println("No SSH credentials have been used yet")
+				return nil
+			}
+			if limit > 0 && len(used) > limit {
+				used = used[:limit]
+			}
+
+			fmt.Println("Recently used SSH credentials:")
+			fmt.Println("---------------------")
+			for i, cred := range used {
+				fmt.Printf("[%d] %s (%s@%s:%d) | Last used: %s | Uses: %d\n",
+					i+1, cred.Name, cred.Username, cred.Host, cred.Port,
+					cred.LastUsedAt.Format("2006-01-02 15:04"), cred.UseCount)
+			}
+			fmt.Println("---------------------")
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum number of credentials to show (0 for all)")
+	cmd.Flags().BoolVarP(&frequent, "frequent", "f", false, "Order by use count instead of last use")
+
+	return cmd
+}
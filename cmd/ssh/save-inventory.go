@@ -0,0 +1,112 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// inventoryFormat picks the inventory format from the file extension
+func inventoryFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return credential.InventoryYAML, nil
+	case ".csv":
+		return credential.InventoryCSV, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s: use a .yaml, .yml or .csv file", path)
+}
+
+// jumpTarget turns a jump host given by credential name into user@host:port
+func jumpTarget(jump string, known map[string]credential.SSHCredential) string {
+	if cred, ok := known[strings.ToLower(jump)]; ok {
+		return fmt.Sprintf("%s@%s:%d", cred.Username, cred.Host, cred.Port)
+	}
+	return jump
+}
+
+// saveFromInventory creates or updates a credential for every host in an
+// inventory file. Hosts that already exist by name only get the fields the
+// inventory sets; new ones start from the configured defaults.
+func saveFromInventory(path string, dryRun bool) error {
+	path = ExpandHome(path)
+	format, err := inventoryFormat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	hosts, err := credential.ParseInventory(data, format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	store, err := credential.NewCredentialStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize credential store: %w", err)
+	}
+
+	known := make(map[string]credential.SSHCredential)
+	for _, cred := range store.ListCredentials() {
+		known[cred.Name] = cred
+	}
+
+	entries := make([]credential.BundleEntry, 0, len(hosts))
+	for _, host := range hosts {
+		cred := host.Credential()
+		cred.Name = strings.ToLower(cred.Name)
+		cred.KeyPath = ExpandHome(cred.KeyPath)
+		if _, exists := known[cred.Name]; !exists {
+			if cred.Port == 0 {
+				cred.Port = defaults().Port
+			}
+			if cred.Username == "" {
+				cred.Username = defaults().User
+			}
+			if cred.AuthType == "" {
+				cred.AuthType = credential.KeyFile
+			}
+			if cred.AuthType == credential.KeyFile && cred.KeyPath == "" && cred.KeyRef == "" {
+				cred.KeyPath = DefaultKeyPath()
+			}
+		}
+		if jump, ok := cred.SSHOptions["ProxyJump"]; ok {
+			cred.SetSSHOption("ProxyJump", jumpTarget(jump, known))
+		}
+		// Later hosts can jump through earlier ones
+		if _, exists := known[cred.Name]; !exists && cred.Host != "" {
+			known[cred.Name] = cred
+		}
+		entries = append(entries, credential.BundleEntry{SSHCredential: cred})
+	}
+
+	items := store.PlanImport(entries, credential.ImportUpdateByName)
+	counts := make(map[credential.ImportAction]int)
+	for _, item := range items {
+		fmt.Println(item)
+		counts[item.Action]++
+	}
+	summary := "Created %d, updated %d, skipped %d unchanged, %d failed\n"
+	if dryRun {
+		summary = "Would create %d, update %d, skip %d unchanged, %d failed\n"
+	}
+	fmt.Printf(summary,
+		counts[credential.ImportAdd], counts[credential.ImportUpdate], counts[credential.ImportSkipped], counts[credential.ImportInvalid])
+
+	if dryRun {
+		fmt.Println("Dry run: no changes made.")
+		return nil
+	}
+	if err := store.ApplyImport(items); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	if counts[credential.ImportInvalid] > 0 {
+		return fmt.Errorf("%d hosts could not be saved", counts[credential.ImportInvalid])
+	}
+	return nil
+}
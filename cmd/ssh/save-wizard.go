@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -13,20 +12,48 @@ import (
 )
 
 func NewSaveWizardCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:     "wizard [user@host[:port] ...]",
+	var (
+		authType string
+		secrets  secretFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:     "wizard [user@host[:port] | ssh://user@host:port ...]",
 		Short:   "Add one or more SSH credentials quickly",
 		Aliases: []string{"w", "wiz"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("please provide at least one connection string (user@host[:port])")
 			}
+			if err := secrets.checkKeyring(""); err != nil {
+				return err
+			}
 
 			store, err := credential.NewCredentialStore()
 			if err != nil {
 				return fmt.Errorf("failed to open credential store: %w", err)
 			}
 
+			// A password source implies password auth unless the type was set explicitly
+			if secrets.provided() && !cmd.Flags().Changed("auth-type") {
+				authType = string(credential.Password)
+			}
+
+			var password, passwordRef string
+			switch credential.AuthType(authType) {
+			case credential.Password:
+				if secrets.provided() {
+					if password, passwordRef, err = secrets.values(); err != nil {
+						return err
+					}
+				} else {
+					password = promptForPassword("Enter password for all connections")
+				}
+			case credential.KeyFile:
+			default:
+				return fmt.Errorf("invalid authentication type: use 'password' or 'key'")
+			}
+
 			reader := bufio.NewReader(os.Stdin)
 
 			for _, connStr := range args {
@@ -35,24 +62,16 @@ func NewSaveWizardCmd() *cobra.Command {
 					continue
 				}
 
-				// Parse connection string
-				atIdx := strings.Index(connStr, "@")
-				if atIdx == -1 {
-					fmt.Printf("Invalid connection string: %s\n", connStr)
+				target, err := credential.ParseTarget(connStr, defaults().User, defaults().Port)
+				if err != nil {
+					fmt.Printf("Skipping %s: %v\n", connStr, err)
 					continue
 				}
-				username := connStr[:atIdx]
-				hostPort := connStr[atIdx+1:]
-				port := 22
-				host := hostPort
-				colonIdx := strings.LastIndex(hostPort, ":")
-				if colonIdx != -1 {
-					host = hostPort[:colonIdx]
-					portStr := hostPort[colonIdx+1:]
-					if p, err := strconv.Atoi(portStr); err == nil {
-						port = p
-					}
+				if target.User == "" {
+					fmt.Printf("Invalid connection string: %s (no user given and no default user configured)\n", connStr)
+					continue
 				}
+				username, host, port := target.User, target.Host, target.Port
 
 				// Prompt for connection name and ensure uniqueness
 				var name string
@@ -78,9 +97,11 @@ func NewSaveWizardCmd() *cobra.Command {
 					break
 				}
 
-				// For fast mode, always use key auth and default key path
-				authType := credential.KeyFile
-				keyPath := os.ExpandEnv("$HOME/.ssh/id_rsa")
+				// For fast mode, use the default key path unless a password was given
+				var keyPath string
+				if credential.AuthType(authType) == credential.KeyFile {
+					keyPath = DefaultKeyPath()
+				}
 
 				id, err := credential.GenerateID()
 				if err != nil {
@@ -89,25 +110,39 @@ func NewSaveWizardCmd() *cobra.Command {
 				}
 
 				cred := credential.SSHCredential{
-					ID:        id,
-					Name:      name,
-					Host:      host,
-					Port:      port,
-					Username:  username,
-					AuthType:  authType,
-					KeyPath:   keyPath,
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
+					ID:       id,
+					Name:     name,
+					Host:     host,
+					Port:     port,
+					Username: username,
+					AuthType: credential.AuthType(authType),
+					Password: password,
+					KeyPath:  keyPath,
+
+					PasswordRef: passwordRef,
+					CreatedAt:   time.Now(),
+					UpdatedAt:   time.Now(),
 				}
 
+				if err := secrets.protect(&cred); err != nil {
+					fmt.Printf("Failed to save %s: %v\n", connStr, err)
+					continue
+				}
+
+				warnDuplicates(store, cred)
 				if err := store.SaveCredential(cred); err != nil {
 					fmt.Printf("Failed to save %s: %v\n", connStr, err)
 					continue
 				}
-				fmt.Printf("Saved: %s (%s@%s:%d)\n", name, username, host, port)
+				fmt.Printf("Saved: %s (%s)\n", name, target)
 			}
 
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&authType, "auth-type", "a", string(credential.KeyFile), "Authentication type for all connections (password/key)")
+	secrets.register(cmd)
+
+	return cmd
 }
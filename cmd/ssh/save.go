@@ -1,7 +1,9 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,9 +15,18 @@ import (
 	"golang.org/x/term"
 )
 
-func promptForNewName(store *credential.CredentialStore, originalName string) string {
+// promptForNewName asks for another name when originalName is taken. Without
+// a terminal, or once stdin has run out, there is nobody to ask.
+func promptForNewName(store *credential.CredentialStore, originalName string) (string, error) {
+	exists := fmt.Errorf("connection name '%s' already exists", originalName)
+	if !stdinIsTerminal() {
+		return "", exists
+	}
 	for {
-		newName := promptForInput(fmt.Sprintf("Connection name '%s' already exists. Enter new name", originalName))
+		newName, err := readInput(fmt.Sprintf("Connection name '%s' already exists. Enter new name", originalName))
+		if err != nil {
+			return "", exists
+		}
 		if newName == "" {
 			fmt.Println("Name cannot be empty. Try again.")
 			continue
@@ -23,7 +34,7 @@ func promptForNewName(store *credential.CredentialStore, originalName string) st
 
 		// Check if the new name also exists
 		if cred, _ := store.GetCredential(newName); cred == nil {
-			return newName
+			return newName, nil
 		}
 		fmt.Printf("Connection name '%s' also exists. Try a different name.\n", newName)
 	}
@@ -31,10 +42,20 @@ func promptForNewName(store *credential.CredentialStore, originalName string) st
 
 // promptForInput reads user input from stdin
 func promptForInput(prompt string) string {
+	input, _ := readInput(prompt)
+	return input
+}
+
+// readInput reads user input from stdin. An empty line is an empty answer;
+// the end of input is io.EOF, so callers asking again can stop.
+func readInput(prompt string) (string, error) {
 	fmt.Printf("%s: ", prompt)
 	var input string
-	fmt.Scanln(&input)
-	return strings.TrimSpace(input)
+	if _, err := fmt.Scanln(&input); errors.Is(err, io.EOF) {
+		fmt.Println()
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
 }
 
 // promptForPassword reads password input securely without echoing
@@ -48,8 +69,13 @@ func promptForPassword(prompt string) string {
 	return string(password)
 }
 
-// getDefaultKeyPath returns the default SSH key path
-func getDefaultKeyPath() string {
+// DefaultKeyPath returns the configured default key, or the first common
+// SSH key found in ~/.ssh
+func DefaultKeyPath() string {
+	if key := defaults().Key; key != "" {
+		return ExpandHome(key)
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -72,18 +98,39 @@ func NewSaveCmd() *cobra.Command {
 	var (
 		name     string
 		host     string
-		port     int = 22 // Default port
+		port     int
 		username string
 		password string
 		keyPath  string
 		authType string = string(credential.KeyFile) // Default auth type
+		keyRef   string
+		certPath string
+		keyPass  string
+		options  []string
+		extra    []string
+		remote   string
+		from     string
+		dryRun   bool
+		secrets  secretFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:     "save",
 		Short:   "Save new SSH credentials",
 		Aliases: []string{"s", "add", "a"},
+		Example: "  ssh-cli ssh save -n prod -H 10.0.0.5 -u deploy\n  ssh-cli ssh save --from inventory.yaml --dry-run",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if from != "" {
+				cmd.SilenceUsage = true
+				return saveFromInventory(from, dryRun)
+			}
+			if dryRun {
+				return fmt.Errorf("--dry-run needs --from")
+			}
+			if err := secrets.checkKeyring(password); err != nil {
+				return err
+			}
+
 			store, err := credential.NewCredentialStore()
 			if err != nil {
 				return fmt.Errorf("failed to initialize credential store: %w", err)
@@ -93,7 +140,10 @@ func NewSaveCmd() *cobra.Command {
 			// Interactive prompts for missing required fields
 			if name == "" {
 				for {
-					name = promptForInput("Enter connection name")
+					name, err = readInput("Enter connection name")
+					if err != nil {
+						return fmt.Errorf("no connection name given")
+					}
 					name = strings.ToLower(strings.TrimSpace(name)) // Normalize here
 
 					if name == "" {
@@ -103,7 +153,9 @@ func NewSaveCmd() *cobra.Command {
 					if cred, _ := store.GetCredential(name); cred == nil {
 						break
 					}
-					name = promptForNewName(store, name)
+					if name, err = promptForNewName(store, name); err != nil {
+						return err
+					}
 					name = strings.ToLower(strings.TrimSpace(name)) // Normalize again after prompt
 					break
 				}
@@ -111,7 +163,9 @@ func NewSaveCmd() *cobra.Command {
 				// Check if name from flag already exists
 				name = strings.ToLower(strings.TrimSpace(name)) // Normalize here
 				if cred, _ := store.GetCredential(name); cred != nil {
-					name = promptForNewName(store, name)
+					if name, err = promptForNewName(store, name); err != nil {
+						return err
+					}
 					name = strings.ToLower(strings.TrimSpace(name)) // Normalize again after prompt
 				}
 			}
@@ -119,11 +173,34 @@ func NewSaveCmd() *cobra.Command {
 			if host == "" {
 				host = promptForInput("Enter host address")
 			}
+			// The host may be a full connection string; explicit --user and --port win
+			target, err := credential.ParseTarget(host, "", 0)
+			if err != nil {
+				return err
+			}
+			host = target.Host
+			if target.User != "" && username == "" {
+				username = target.User
+			}
+			if !cmd.Flags().Changed("port") {
+				port = target.Port
+				if port == 0 {
+					port = defaults().Port
+				}
+			}
 
+			if username == "" {
+				username = defaults().User
+			}
 			if username == "" {
 				username = promptForInput("Enter username")
 			}
 
+			// A password source implies password auth unless the type was set explicitly
+			if (secrets.provided() || password != "") && !cmd.Flags().Changed("auth-type") {
+				authType = string(credential.Password)
+			}
+
 			// Only prompt for auth type if explicitly set to empty
 			if authType == "" {
 				fmt.Println("Authentication type (password/key)")
@@ -131,16 +208,29 @@ func NewSaveCmd() *cobra.Command {
 			}
 
 			var auth credential.AuthType
+			var passwordRef string
 			switch authType {
 			case "password":
 				auth = credential.Password
-				if password == "" {
+				if password == "" && secrets.provided() {
+					if password, passwordRef, err = secrets.values(); err != nil {
+						return err
+					}
+				}
+				if password == "" && passwordRef == "" {
 					password = promptForPassword("Enter password")
 				}
 			case "key":
 				auth = credential.KeyFile
+				if keyRef != "" {
+					if err := credential.ValidateSecretRef(keyRef); err != nil {
+						return err
+					}
+					keyPath = ""
+					break
+				}
 				if keyPath == "" {
-					defaultKey := getDefaultKeyPath()
+					defaultKey := DefaultKeyPath()
 					keyPath = defaultKey
 					fmt.Printf("Using default key: %s\n", defaultKey)
 				}
@@ -174,8 +264,32 @@ func NewSaveCmd() *cobra.Command {
 				KeyPath:   keyPath,
 				CreatedAt: now,
 				UpdatedAt: now,
+
+				PasswordRef: passwordRef,
+				KeyRef:      keyRef,
+				CertPath:    ExpandHome(certPath),
+				KeyPassRef:  keyPass,
+				ExtraArgs:   extra,
+
+				RemoteCommand: strings.TrimSpace(remote),
+			}
+			for _, option := range options {
+				key, value, err := credential.ParseSSHOption(option)
+				if err != nil {
+					return err
+				}
+				cred.SetSSHOption(key, value)
+			}
+
+			if err := checkKey(&cred); err != nil {
+				return err
+			}
+
+			if err := secrets.protect(&cred); err != nil {
+				return err
 			}
 
+			warnDuplicates(store, cred)
 			if err := store.SaveCredential(cred); err != nil {
 				return fmt.Errorf("failed to save credential: %w", err)
 			}
@@ -187,12 +301,23 @@ func NewSaveCmd() *cobra.Command {
 
 	// Add flags with defaults
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the SSH connection (required)")
-	cmd.Flags().StringVarP(&host, "host", "H", "", "Host address (required)")
-	cmd.Flags().IntVarP(&port, "port", "p", 22, "SSH port")
-	cmd.Flags().StringVarP(&username, "user", "u", "", "SSH username (required)")
+	cmd.Flags().StringVarP(&host, "host", "H", "", "Host address or user@host[:port] (required)")
+	cmd.Flags().IntVarP(&port, "port", "p", 0, "SSH port (defaults to the configured port)")
+	cmd.Flags().StringVarP(&username, "user", "u", "", "SSH username (defaults to the configured user)")
 	cmd.Flags().StringVarP(&password, "password", "P", "", "SSH password (for password auth)")
-	cmd.Flags().StringVarP(&keyPath, "key", "k", getDefaultKeyPath(), "SSH private key path")
+	cmd.Flags().StringVarP(&keyPath, "key", "k", "", "SSH private key path (defaults to the configured key)")
 	cmd.Flags().StringVarP(&authType, "auth-type", "a", "key", "Authentication type (password/key)")
+	cmd.Flags().StringVar(&keyRef, "key-ref", "", "Resolve the private key at connect time (vault://path#field, file://path, env://VAR)")
+	cmd.Flags().StringVar(&certPath, "cert", "", "SSH certificate to present with the key")
+	cmd.Flags().StringVar(&keyPass, "key-passphrase-ref", "", "Passphrase of an encrypted key (vault://path#field, file://path, env://VAR, keyring://id)")
+	cmd.Flags().StringArrayVarP(&options, "option", "o", nil, "ssh option for this host as Key=Value (e.g. ServerAliveInterval=30), can be repeated")
+	cmd.Flags().StringArrayVar(&extra, "extra-arg", nil, "Extra argument passed to ssh for this host, can be repeated")
+	cmd.Flags().StringVar(&remote, "remote-command", "", "Command to run on interactive connect (e.g. 'tmux new -A -s main')")
+	cmd.Flags().StringVar(&from, "from", "", "Create or update many credentials from a YAML or CSV inventory file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "With --from, show what would change without saving")
+	secrets.register(cmd)
+	// Passwords on the command line leak into shell history and ps output
+	cmd.Flags().MarkDeprecated("password", "use --password-stdin, --password-file or --password-env instead")
 
 	return cmd
 }
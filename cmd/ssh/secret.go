@@ -0,0 +1,158 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// secretFlags are the non-interactive ways to pass a password. Passwords are never
+// accepted as flag values, so they don't end up in shell history or ps output.
+type secretFlags struct {
+	stdin   bool
+	file    string
+	env     string
+	ref     string
+	keyring bool
+}
+
+func (f *secretFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.stdin, "password-stdin", false, "Read the password from stdin")
+	cmd.Flags().StringVar(&f.file, "password-file", "", "Read the password from a file")
+	cmd.Flags().StringVar(&f.env, "password-env", "", "Read the password from an environment variable")
+	cmd.Flags().StringVar(&f.ref, "password-ref", "", "Resolve the password at connect time (vault://path#field, file://path, env://VAR)")
+	cmd.Flags().BoolVar(&f.keyring, "keyring", false, "Keep the password in the system keyring instead of the credential store")
+}
+
+// apply sets the credential's password from the given source, either as a reference
+// or by reading it now
+func (f *secretFlags) apply(cred *credential.SSHCredential) error {
+	password, ref, err := f.values()
+	if err != nil {
+		return err
+	}
+	cred.Password = password
+	if ref != "" || !strings.HasPrefix(cred.PasswordRef, credential.KeyringScheme) {
+		cred.PasswordRef = ref
+	}
+	return nil
+}
+
+// values returns either the password read from its source or the reference to resolve later
+func (f *secretFlags) values() (password, ref string, err error) {
+	if f.ref == "" {
+		password, err = f.read()
+		return password, "", err
+	}
+	if f.stdin || f.file != "" || f.env != "" || f.keyring {
+		return "", "", errors.New("--password-ref cannot be combined with other password flags")
+	}
+	if err := credential.ValidateSecretRef(f.ref); err != nil {
+		return "", "", err
+	}
+	return "", strings.TrimSpace(f.ref), nil
+}
+
+// protect moves a plaintext password into the keyring when --keyring was given or
+// the credential already keeps its password there
+func (f *secretFlags) protect(cred *credential.SSHCredential) error {
+	if cred.Password == "" || (!f.keyring && !strings.HasPrefix(cred.PasswordRef, credential.KeyringScheme)) {
+		return nil
+	}
+	kr, err := credential.OpenKeyring()
+	if err != nil {
+		return err
+	}
+	if err := cred.MovePasswordToKeyring(kr); err != nil {
+		return fmt.Errorf("failed to store password in keyring: %w", err)
+	}
+	return nil
+}
+
+// provided reports whether any password source was given
+func (f *secretFlags) provided() bool {
+	return f.stdin || f.file != "" || f.env != "" || f.ref != ""
+}
+
+// checkKeyring rejects --keyring when no password is given to keep there,
+// instead of prompting for one or failing once the credential is validated.
+// password is a password already given some other way.
+func (f *secretFlags) checkKeyring(password string) error {
+	if f.keyring && !f.provided() && password == "" {
+		return errors.New("--keyring needs a password: pass one with --password-stdin, --password-file or --password-env")
+	}
+	return nil
+}
+
+// read returns the password from the single source that was given
+func (f *secretFlags) read() (string, error) {
+	sources := 0
+	for _, set := range []bool{f.stdin, f.file != "", f.env != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", errors.New("use only one of --password-stdin, --password-file and --password-env")
+	}
+
+	switch {
+	case f.stdin:
+		return readPasswordStdin(os.Stdin)
+	case f.file != "":
+		data, err := os.ReadFile(ExpandHome(f.file))
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		password := strings.TrimRight(string(data), "\r\n")
+		if password == "" {
+			return "", fmt.Errorf("password file %s is empty", f.file)
+		}
+		return password, nil
+	case f.env != "":
+		password, ok := os.LookupEnv(f.env)
+		if !ok || password == "" {
+			return "", fmt.Errorf("environment variable %s is not set", f.env)
+		}
+		return password, nil
+	}
+	return "", errors.New("no password source given")
+}
+
+// stdinIsTerminal reports whether prompts can be shown to the user
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// readPasswordStdin reads a password piped on stdin, dropping the trailing newline
+func readPasswordStdin(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", errors.New("no password provided on stdin")
+	}
+	return password, nil
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	path = strings.TrimSpace(path)
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
@@ -8,7 +8,7 @@ func NewSSHCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "ssh",
 		Short:   "Manage and connect to SSH servers",
-		Long:    `A suite of commands to save, list, delete, and connect to SSH servers.`,
+		Long:    `A suite of commands to save, list, delete, restore, and connect to SSH servers.`,
 		Aliases: []string{"s", "ss"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
@@ -21,6 +21,10 @@ func NewSSHCmd() *cobra.Command {
 	cmd.AddCommand(NewDeleteCmd())
 	cmd.AddCommand(NewConnectCmd())
 	cmd.AddCommand(NewUpdateCmd())
+	cmd.AddCommand(NewTrashCmd())
+	cmd.AddCommand(NewRecentCmd())
+	cmd.AddCommand(NewCertCmd())
+	cmd.AddCommand(NewDedupeCmd())
 
 	return cmd
 }
@@ -0,0 +1,128 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/spf13/cobra"
+)
+
+func newTrashListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List deleted SSH credentials",
+		Aliases: []string{"ls", "l"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			trash := store.ListTrash()
+			if len(trash) == 0 {
+				fmt.Println("Trash is empty")
+				return nil
+			}
+
+			fmt.Println("Deleted SSH credentials:")
+			fmt.Println("---------------------")
+			for i, cred := range trash {
+				deletedAt := "unknown"
+				if cred.DeletedAt != nil {
+					deletedAt = cred.DeletedAt.Format("2006-01-02 15:04")
+				}
+				fmt.Printf("[%d] Name: %s | %s@%s:%d | Deleted: %s\n", i+1, cred.Name, cred.Username, cred.Host, cred.Port, deletedAt)
+			}
+			fmt.Println("---------------------")
+			fmt.Printf("Deleted credentials are purged after %d days\n", int(credential.TrashRetention().Hours()/24))
+			return nil
+		},
+	}
+}
+
+func newTrashRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "restore <name>",
+		Short:   "Restore a deleted SSH credential",
+		Aliases: []string{"r"},
+		Args:    cobra.ExactArgs(1),
+
+		ValidArgsFunction: completeTrashNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			name := strings.ToLower(strings.TrimSpace(args[0]))
+			if err := store.RestoreCredential(name); err != nil {
+				return fmt.Errorf("failed to restore credential: %w", err)
+			}
+
+			fmt.Printf("Successfully restored SSH credential %s\n", name)
+			return nil
+		},
+	}
+}
+
+func newTrashEmptyCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently remove all deleted SSH credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			trash := store.ListTrash()
+			if len(trash) == 0 {
+				fmt.Println("Trash is already empty")
+				return nil
+			}
+
+			if !force {
+				fmt.Printf("Permanently delete %d credential(s)? (y/n): ", len(trash))
+				var response string
+				fmt.Scanln(&response)
+				if strings.ToLower(response) != "y" {
+					fmt.Println("Cancelled")
+					return nil
+				}
+			}
+
+			if err := store.EmptyTrash(); err != nil {
+				return fmt.Errorf("failed to empty trash: %w", err)
+			}
+
+			fmt.Printf("Permanently deleted %d credential(s)\n", len(trash))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+func NewTrashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "trash",
+		Short:   "Manage deleted SSH credentials",
+		Aliases: []string{"t"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newTrashListCmd())
+	cmd.AddCommand(newTrashRestoreCmd())
+	cmd.AddCommand(newTrashEmptyCmd())
+
+	return cmd
+}
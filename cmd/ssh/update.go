@@ -9,119 +9,300 @@ import (
 	"time"
 
 	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/tui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// EditCredential prompts for new values for each field of a credential and saves it.
+// Blank answers keep the current value.
+func EditCredential(reader *bufio.Reader, store *credential.CredentialStore, cred *credential.SSHCredential, nameOrID string) error {
+	// Print old credential
+	fmt.Println("\nCurrent credential values:")
+	fmt.Printf("Host: %s\n", cred.Host)
+	fmt.Printf("Port: %d\n", cred.Port)
+	fmt.Printf("Username: %s\n", cred.Username)
+	fmt.Printf("AuthType: %s\n", cred.AuthType)
+	if cred.AuthType == credential.Password {
+		fmt.Printf("Password: (hidden)\n")
+	} else {
+		fmt.Printf("KeyPath: %s\n", cred.KeyPath)
+	}
+	fmt.Println("Leave blank to keep current value.")
+
+	fmt.Printf("New Host [%s]: ", cred.Host)
+	host, _ := reader.ReadString('\n')
+	host = strings.TrimSpace(host)
+	if host != "" {
+		cred.Host = host
+	}
+
+	fmt.Printf("New Port [%d]: ", cred.Port)
+	portStr, _ := reader.ReadString('\n')
+	portStr = strings.TrimSpace(portStr)
+	if portStr != "" {
+		if port, err := strconv.Atoi(portStr); err == nil {
+			cred.Port = port
+		}
+	}
+
+	fmt.Printf("New Username [%s]: ", cred.Username)
+	username, _ := reader.ReadString('\n')
+	username = strings.TrimSpace(username)
+	if username != "" {
+		cred.Username = username
+	}
+
+	fmt.Printf("New AuthType [%s] [password/key]: ", cred.AuthType)
+	authType, _ := reader.ReadString('\n')
+	authType = strings.TrimSpace(authType)
+	if authType != "" {
+		cred.SetAuthType(credential.AuthType(authType))
+	}
+
+	if cred.AuthType == credential.Password {
+		password := promptForPassword("New Password (leave blank to keep)")
+		if password != "" {
+			cred.Password = password
+			if !strings.HasPrefix(cred.PasswordRef, credential.KeyringScheme) {
+				cred.PasswordRef = ""
+			}
+		}
+	} else {
+		fmt.Printf("New KeyPath [%s]: ", cred.KeyPath)
+		keyPath, _ := reader.ReadString('\n')
+		keyPath = strings.TrimSpace(keyPath)
+		if keyPath != "" {
+			cred.KeyPath = keyPath
+			if err := checkKey(cred); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Keep a new password in the keyring if the old one was there
+	if err := (&secretFlags{}).protect(cred); err != nil {
+		return err
+	}
+
+	cred.UpdatedAt = time.Now()
+
+	if err := store.UpdateCredential(nameOrID, *cred); err != nil {
+		return fmt.Errorf("failed to update credential: %w", err)
+	}
+	return nil
+}
+
+// applyUpdateFlags changes only the fields whose flags were given on the command line
+func applyUpdateFlags(cmd *cobra.Command, cred *credential.SSHCredential, opts updateOptions) error {
+	flags := cmd.Flags()
+	if flags.Changed("host") {
+		cred.Host = strings.TrimSpace(opts.host)
+	}
+	if flags.Changed("port") {
+		cred.Port = opts.port
+	}
+	if flags.Changed("user") {
+		cred.Username = strings.TrimSpace(opts.username)
+	}
+	if flags.Changed("auth-type") {
+		cred.SetAuthType(credential.AuthType(opts.authType))
+	}
+	if flags.Changed("key") {
+		cred.KeyPath = ExpandHome(opts.keyPath)
+	}
+	if flags.Changed("key-passphrase-ref") {
+		if opts.keyPass != "" {
+			if err := credential.ValidateSecretRef(opts.keyPass); err != nil {
+				return err
+			}
+		}
+		cred.KeyPassRef = strings.TrimSpace(opts.keyPass)
+	}
+	if flags.Changed("cert") {
+		cred.CertPath = ExpandHome(opts.certPath)
+	}
+	if flags.Changed("key-ref") {
+		if opts.keyRef != "" {
+			if err := credential.ValidateSecretRef(opts.keyRef); err != nil {
+				return err
+			}
+		}
+		cred.KeyRef = strings.TrimSpace(opts.keyRef)
+	}
+	if opts.secrets.provided() {
+		if err := opts.secrets.apply(cred); err != nil {
+			return err
+		}
+	}
+
+	for _, option := range opts.options {
+		key, value, err := credential.ParseSSHOption(option)
+		if err != nil {
+			return err
+		}
+		cred.SetSSHOption(key, value)
+	}
+	for _, key := range opts.removeOptions {
+		cred.RemoveSSHOption(strings.TrimSpace(key))
+	}
+	if opts.clearExtra {
+		cred.ExtraArgs = nil
+	}
+	cred.ExtraArgs = append(cred.ExtraArgs, opts.extra...)
+	if flags.Changed("remote-command") {
+		cred.RemoteCommand = strings.TrimSpace(opts.remoteCommand)
+	}
+
+	for _, tag := range opts.addTags {
+		key, value, err := credential.ParseTag(tag)
+		if err != nil {
+			return err
+		}
+		if cred.Tags == nil {
+			cred.Tags = make(map[string]string)
+		}
+		cred.Tags[key] = value
+	}
+	for _, key := range opts.removeTags {
+		delete(cred.Tags, strings.TrimSpace(key))
+	}
+	if len(cred.Tags) == 0 {
+		cred.Tags = nil
+	}
+
+	if flags.Changed("key") || flags.Changed("auth-type") || flags.Changed("key-passphrase-ref") {
+		if err := checkKey(cred); err != nil {
+			return err
+		}
+	}
+
+	if opts.secrets.keyring && cred.Password == "" && cred.PasswordRef == "" {
+		return fmt.Errorf("--keyring needs a password: pass one with --password-stdin, --password-file or --password-env")
+	}
+	return opts.secrets.protect(cred)
+}
+
+// updateFlagsGiven reports whether any of update's own flags were given.
+// Global flags such as --allow-insecure-permissions do not count.
+func updateFlagsGiven(cmd *cobra.Command) bool {
+	given := false
+	cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		given = given || flag.Changed
+	})
+	return given
+}
+
+type updateOptions struct {
+	host          string
+	port          int
+	username      string
+	authType      string
+	keyPath       string
+	keyRef        string
+	certPath      string
+	keyPass       string
+	secrets       secretFlags
+	addTags       []string
+	options       []string
+	removeOptions []string
+	extra         []string
+	clearExtra    bool
+	remoteCommand string
+	removeTags    []string
+}
+
 func NewUpdateCmd() *cobra.Command {
-	return &cobra.Command{
+	var opts updateOptions
+
+	cmd := &cobra.Command{
 		Use:     "update [credential name or id]",
 		Short:   "Update an existing SSH credential",
 		Aliases: []string{"u", "up"},
+		Example: "  ssh-cli ssh update prod --port 2222 --key ~/.ssh/new --user deploy --add-tag env=prod",
+		Args:    cobra.MaximumNArgs(1),
+
+		ValidArgsFunction: CompleteCredentialNames,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Print("Updating SSH credential...\n")
-			reader := bufio.NewReader(os.Stdin)
+			nonInteractive := updateFlagsGiven(cmd)
+			if !nonInteractive && !stdinIsTerminal() {
+				return fmt.Errorf("stdin is not a terminal: pass the fields to change as flags (see --help)")
+			}
+			if nonInteractive && (len(args) == 0 || strings.TrimSpace(args[0]) == "") {
+				return fmt.Errorf("a credential name is required when updating with flags")
+			}
+
 			store, err := credential.NewCredentialStore()
 			if err != nil {
 				return fmt.Errorf("failed to open credential store: %w", err)
 			}
 
-			var cred *credential.SSHCredential
-			var nameOrID string
-
-			if len(args) > 0 && strings.TrimSpace(args[0]) != "" {
-				nameOrID = strings.TrimSpace(args[0])
-				cred, err = store.GetCredential(nameOrID)
+			if nonInteractive {
+				nameOrID := strings.TrimSpace(args[0])
+				cred, err := store.FindCredential(nameOrID)
 				if err != nil {
 					return fmt.Errorf("credential not found: %w", err)
 				}
-			} else {
-				// List all credentials
-				creds := store.ListCredentials()
-				if len(creds) == 0 {
-					return fmt.Errorf("no credentials found")
+				name := cred.Name
+				before := *cred
+				if err := applyUpdateFlags(cmd, cred, opts); err != nil {
+					return err
 				}
-				fmt.Println("Available credentials:")
-				for i, c := range creds {
-					fmt.Printf("[%d] %s (ID: %s)\n", i+1, c.Name, c.ID)
+				if len(credential.ChangedFields(before, *cred)) == 0 {
+					return fmt.Errorf("nothing to update: the flags leave %s unchanged", name)
 				}
-				fmt.Print("Select credential by number: ")
-				choiceStr, _ := reader.ReadString('\n')
-				choiceStr = strings.TrimSpace(choiceStr)
-				choice, err := strconv.Atoi(choiceStr)
-				if err != nil || choice < 1 || choice > len(creds) {
-					return fmt.Errorf("invalid selection")
+				cred.UpdatedAt = time.Now()
+				if err := store.UpdateCredential(name, *cred); err != nil {
+					return fmt.Errorf("failed to update credential: %w", err)
 				}
-				cred = &creds[choice-1]
-				nameOrID = cred.Name
-			}
-
-			// Print old credential
-			fmt.Println("\nCurrent credential values:")
-			fmt.Printf("Host: %s\n", cred.Host)
-			fmt.Printf("Port: %d\n", cred.Port)
-			fmt.Printf("Username: %s\n", cred.Username)
-			fmt.Printf("AuthType: %s\n", cred.AuthType)
-			if cred.AuthType == credential.Password {
-				fmt.Printf("Password: (hidden)\n")
-			} else {
-				fmt.Printf("KeyPath: %s\n", cred.KeyPath)
-			}
-			fmt.Println("Leave blank to keep current value.")
-
-			fmt.Printf("New Host [%s]: ", cred.Host)
-			host, _ := reader.ReadString('\n')
-			host = strings.TrimSpace(host)
-			if host != "" {
-				cred.Host = host
+				fmt.Println("Credential updated successfully.")
+				return nil
 			}
 
-			fmt.Printf("New Port [%d]: ", cred.Port)
-			portStr, _ := reader.ReadString('\n')
-			portStr = strings.TrimSpace(portStr)
-			if portStr != "" {
-				if port, err := strconv.Atoi(portStr); err == nil {
-					cred.Port = port
+			if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+				// Pick the credential in the UI
+				if len(store.ListCredentials()) == 0 {
+					return fmt.Errorf("no credentials found")
 				}
+				return Browse(store, BrowseOptions{
+					Enter:  tui.ActionEdit,
+					Until:  tui.ActionEdit,
+					Status: " enter: edit the current credential  /: search  q: cancel",
+				})
 			}
 
-			fmt.Printf("New Username [%s]: ", cred.Username)
-			username, _ := reader.ReadString('\n')
-			username = strings.TrimSpace(username)
-			if username != "" {
-				cred.Username = username
-			}
-
-			fmt.Printf("New AuthType [%s] [password/key]: ", cred.AuthType)
-			authType, _ := reader.ReadString('\n')
-			authType = strings.TrimSpace(authType)
-			if authType != "" {
-				cred.AuthType = credential.AuthType(authType)
-			}
-
-			if cred.AuthType == credential.Password {
-				fmt.Printf("New Password (leave blank to keep): ")
-				password, _ := reader.ReadString('\n')
-				password = strings.TrimSpace(password)
-				if password != "" {
-					cred.Password = password
-				}
-			} else {
-				fmt.Printf("New KeyPath [%s]: ", cred.KeyPath)
-				keyPath, _ := reader.ReadString('\n')
-				keyPath = strings.TrimSpace(keyPath)
-				if keyPath != "" {
-					cred.KeyPath = keyPath
-				}
+			fmt.Print("Updating SSH credential...\n")
+			cred, err := store.FindCredential(strings.TrimSpace(args[0]))
+			if err != nil {
+				return fmt.Errorf("credential not found: %w", err)
 			}
-
-			cred.UpdatedAt = time.Now()
-
-			if err := store.UpdateCredential(nameOrID, *cred); err != nil {
-				return fmt.Errorf("failed to update credential: %w", err)
+			if err := EditCredential(bufio.NewReader(os.Stdin), store, cred, cred.Name); err != nil {
+				return err
 			}
 
 			fmt.Println("Credential updated successfully.")
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&opts.host, "host", "H", "", "New host address")
+	cmd.Flags().IntVarP(&opts.port, "port", "p", 0, "New SSH port")
+	cmd.Flags().StringVarP(&opts.username, "user", "u", "", "New SSH username")
+	cmd.Flags().StringVarP(&opts.authType, "auth-type", "a", "", "New authentication type (password/key)")
+	cmd.Flags().StringVarP(&opts.keyPath, "key", "k", "", "New SSH private key path")
+	cmd.Flags().StringVar(&opts.keyRef, "key-ref", "", "Resolve the private key at connect time (vault://path#field, file://path, env://VAR); empty to clear")
+	cmd.Flags().StringVar(&opts.certPath, "cert", "", "New SSH certificate path; empty to clear")
+	cmd.Flags().StringVar(&opts.keyPass, "key-passphrase-ref", "", "Passphrase reference for an encrypted key; empty to clear")
+	cmd.Flags().StringArrayVarP(&opts.options, "option", "o", nil, "Add or replace an ssh option as Key=Value, can be repeated")
+	cmd.Flags().StringArrayVar(&opts.removeOptions, "remove-option", nil, "Remove an ssh option by name, can be repeated")
+	cmd.Flags().StringArrayVar(&opts.extra, "extra-arg", nil, "Append an extra argument passed to ssh, can be repeated")
+	cmd.Flags().BoolVar(&opts.clearExtra, "clear-extra-args", false, "Remove all extra ssh arguments")
+	cmd.Flags().StringVar(&opts.remoteCommand, "remote-command", "", "Command to run on interactive connect; empty to clear")
+	opts.secrets.register(cmd)
+	cmd.Flags().StringArrayVar(&opts.addTags, "add-tag", nil, "Add or replace a tag (key=value), can be repeated")
+	cmd.Flags().StringArrayVar(&opts.removeTags, "remove-tag", nil, "Remove a tag by key, can be repeated")
+	_ = cmd.RegisterFlagCompletionFunc("add-tag", CompleteTags)
+	_ = cmd.RegisterFlagCompletionFunc("remove-tag", completeTagKeys)
+
+	return cmd
 }
@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"filippo.io/age"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/cmd/ssh"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/config"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/importer"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// readPassphrase prompts on the terminal without echoing
+func readPassphrase(prompt string) ([]byte, error) {
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return nil, errors.New("a passphrase is needed but stdin is not a terminal")
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	return passphrase, nil
+}
+
+// bundleRecipients returns who an export is encrypted to, if anyone
+func bundleRecipients(encryptTo []string, passphrase bool) ([]age.Recipient, error) {
+	if passphrase {
+		if len(encryptTo) > 0 {
+			return nil, errors.New("--passphrase cannot be combined with --encrypt-to")
+		}
+		first, err := readPassphrase("Bundle passphrase")
+		if err != nil {
+			return nil, err
+		}
+		second, err := readPassphrase("Repeat passphrase")
+		if err != nil {
+			return nil, err
+		}
+		if string(first) != string(second) {
+			return nil, errors.New("passphrases do not match")
+		}
+		recipient, err := age.NewScryptRecipient(string(first))
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{recipient}, nil
+	}
+
+	var recipients []age.Recipient
+	for _, value := range encryptTo {
+		recipient, err := credential.ParseRecipient(ssh.ExpandHome(value))
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// decryptBundle decrypts an encrypted bundle with a passphrase or with the
+// given identities, falling back to the usual SSH keys
+func decryptBundle(data []byte, identityPaths []string) ([]byte, error) {
+	if credential.IsPassphraseEncrypted(data) {
+		passphrase, err := readPassphrase("Bundle passphrase")
+		if err != nil {
+			return nil, err
+		}
+		identity, err := age.NewScryptIdentity(string(passphrase))
+		if err != nil {
+			return nil, err
+		}
+		return credential.Decrypt(data, identity)
+	}
+
+	if len(identityPaths) == 0 {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range []string{"id_ed25519", "id_rsa"} {
+			path := filepath.Join(homeDir, ".ssh", name)
+			if _, err := os.Stat(path); err == nil {
+				identityPaths = append(identityPaths, path)
+			}
+		}
+		if len(identityPaths) == 0 {
+			return nil, errors.New("bundle is encrypted: pass the key to decrypt it with --identity")
+		}
+	}
+
+	var identities []age.Identity
+	for _, path := range identityPaths {
+		path = ssh.ExpandHome(path)
+		loaded, err := credential.LoadIdentity(path, func() ([]byte, error) {
+			return readPassphrase("Passphrase for " + path)
+		})
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, loaded...)
+	}
+	return credential.Decrypt(data, identities...)
+}
+
+func newStoreExportCmd() *cobra.Command {
+	var (
+		format         string
+		output         string
+		includeSecrets bool
+		encryptTo      []string
+		passphrase     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all credentials to a portable JSON or YAML bundle",
+		Example: "  ssh-cli store export --format yaml -o hosts.yaml\n" +
+			"  ssh-cli store export --include-secrets --encrypt-to ~/.ssh/id_ed25519.pub -o laptop.json\n" +
+			"  ssh-cli store export --include-secrets --passphrase -o backup.json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(format)
+			recipients, err := bundleRecipients(encryptTo, passphrase)
+			if err != nil {
+				return err
+			}
+
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			bundle, warnings, err := store.Export(includeSecrets)
+			if err != nil {
+				return err
+			}
+			data, err := credential.EncodeBundle(bundle, format)
+			if err != nil {
+				return err
+			}
+			if len(recipients) > 0 {
+				if data, err = credential.Encrypt(data, recipients...); err != nil {
+					return err
+				}
+			} else if includeSecrets {
+				fmt.Fprintln(os.Stderr, "Warning: the bundle contains secrets in plain text; consider --encrypt-to or --passphrase")
+			}
+
+			for _, warning := range warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+
+			if output == "" || output == "-" {
+				_, err := cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(ssh.ExpandHome(output), data, 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d credentials to %s\n", len(bundle.Credentials), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", credential.BundleJSON, "Bundle format (json/yaml)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the bundle to this file instead of stdout")
+	cmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Include passwords and private keys")
+	cmd.Flags().StringArrayVar(&encryptTo, "encrypt-to", nil, "Encrypt to an age recipient or SSH public key (value or file), can be repeated")
+	cmd.Flags().BoolVar(&passphrase, "passphrase", false, "Encrypt with a passphrase asked for on the terminal")
+
+	return cmd
+}
+
+// readForeignSessions reads another client's export and fills in what it
+// lacks from the configured defaults, printing how each session was mapped
+func readForeignSessions(format, path string) ([]credential.BundleEntry, error) {
+	imp, err := importer.Lookup(format, path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := imp.Import(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Read %d sessions from %s (%s):\n", len(entries), path, imp.Name())
+	bundle := make([]credential.BundleEntry, 0, len(entries))
+	for _, entry := range entries {
+		c := &entry.Credential
+		if c.Name == "" {
+			entry.Map("host", "name", c.Host)
+		}
+		if c.Port == 0 {
+			entry.Map("default", "port", strconv.Itoa(cfg.Port))
+		}
+		if c.Username == "" {
+			entry.Map("default", "username", cfg.User)
+		}
+		if c.Username == "" {
+			entry.Warnings = append(entry.Warnings, "no user given: set a default with 'ssh-cli config set user <name>'")
+		}
+		if c.AuthType == "" {
+			c.AuthType = credential.KeyFile
+		}
+		if c.AuthType == credential.KeyFile && c.KeyPath == "" {
+			entry.Map("default", "key_path", ssh.DefaultKeyPath())
+		}
+		c.KeyPath = ssh.ExpandHome(c.KeyPath)
+
+		fmt.Println("  " + entry.Report())
+		for _, warning := range entry.Warnings {
+			fmt.Printf("    warning: %s\n", warning)
+		}
+		bundle = append(bundle, credential.BundleEntry{SSHCredential: *c})
+	}
+	return bundle, nil
+}
+
+// readBundle reads and if needed decrypts a bundle written by store export
+func readBundle(path string, identities []string) ([]credential.BundleEntry, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(ssh.ExpandHome(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if credential.IsEncrypted(data) {
+		if data, err = decryptBundle(data, identities); err != nil {
+			return nil, err
+		}
+	}
+	bundle, err := credential.DecodeBundle(data)
+	if err != nil {
+		return nil, err
+	}
+	return bundle.Credentials, nil
+}
+
+func newStoreImportCmd() *cobra.Command {
+	var (
+		strategy   string
+		dryRun     bool
+		identities []string
+		from       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import credentials from a bundle or another SSH client",
+		Long: "Import credentials from a JSON or YAML bundle, encrypted or not. Use - to read from stdin.\n" +
+			"With --from, read sessions exported by another client instead: PuTTY (.reg), Remmina (.remmina file or directory),\n" +
+			"MobaXterm (.ini/.mxtsessions), Termius or other CSV exports, an Ansible inventory (INI or YAML),\n" +
+			"or an OpenSSH known_hosts file.\n" +
+			"Strategies for entries that already exist:\n" +
+			"  skip       keep the local credential (default)\n" +
+			"  overwrite  replace the local credential with the same name\n" +
+			"  rename     import under a new name such as prod-2\n" +
+			"  merge      update the local credential with the same ID, import the rest like rename\n" +
+			"  update     update the local credential with the same name with the fields the bundle sets\n" +
+			"Each entry is validated on its own; invalid entries are reported and the rest are imported.",
+		Example: "  ssh-cli store import hosts.yaml --dry-run\n  ssh-cli store import laptop.json --strategy merge\n" +
+			"  ssh-cli store import --from putty sessions.reg --dry-run\n  ssh-cli store import --from auto ~/.ssh/known_hosts",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode, err := credential.ParseImportStrategy(strategy)
+			if err != nil {
+				return err
+			}
+
+			var entries []credential.BundleEntry
+			if from != "" {
+				entries, err = readForeignSessions(from, ssh.ExpandHome(args[0]))
+			} else {
+				entries, err = readBundle(args[0], identities)
+			}
+			if err != nil {
+				return err
+			}
+
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			items := store.PlanImport(entries, mode)
+			counts := make(map[credential.ImportAction]int)
+			for _, item := range items {
+				fmt.Println(item)
+				counts[item.Action]++
+			}
+			fmt.Printf("%d to add, %d to update, %d skipped, %d invalid\n",
+				counts[credential.ImportAdd], counts[credential.ImportUpdate], counts[credential.ImportSkipped], counts[credential.ImportInvalid])
+
+			if dryRun {
+				fmt.Println("Dry run: no changes made.")
+				return nil
+			}
+			if err := store.ApplyImport(items); err != nil {
+				return fmt.Errorf("failed to import: %w", err)
+			}
+			if counts[credential.ImportInvalid] > 0 {
+				return fmt.Errorf("%d entries could not be imported", counts[credential.ImportInvalid])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&strategy, "strategy", "s", string(credential.ImportSkip), "What to do with existing entries (skip/overwrite/rename/merge/update)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without importing")
+	cmd.Flags().StringArrayVarP(&identities, "identity", "i", nil, "age identity or SSH private key to decrypt the bundle, can be repeated")
+	cmd.Flags().StringVar(&from, "from", "", "Import another client's export: auto, "+strings.Join(importer.Names(), ", ")+" or termius")
+
+	return cmd
+}
+
+func newStoreDoctorCmd() *cobra.Command {
+	var (
+		fix bool
+		yes bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the credential store for damage and repair what is safe to repair",
+		Long: "Check credentials.json and its directory: JSON errors with their line and column, missing or\n" +
+			"duplicate IDs and names, invalid auth types and ports, missing key and certificate files, secret\n" +
+			"references that point nowhere, and permissions that let other users read the store.\n" +
+			"With --fix, the problems that have a safe repair are fixed after a backup of the store is written.",
+		Example:      "  ssh-cli store doctor\n  ssh-cli store doctor --fix",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := credential.Doctor()
+			if err != nil {
+				return err
+			}
+			if len(report.Problems) == 0 {
+				fmt.Printf("No problems found in %s\n", report.Path)
+				return nil
+			}
+
+			fmt.Printf("Checked %s:\n", report.Path)
+			for _, problem := range report.Problems {
+				fmt.Printf("  %s\n", problem)
+			}
+			fixable := report.Fixable()
+			fmt.Printf("%d problems, %d can be fixed automatically\n", len(report.Problems), fixable)
+
+			if !fix || fixable == 0 {
+				if fixable > 0 {
+					fmt.Println("Run 'ssh-cli store doctor --fix' to apply the fixes")
+				}
+				return fmt.Errorf("%d problems found", len(report.Problems))
+			}
+			if !yes {
+				if !term.IsTerminal(int(syscall.Stdin)) {
+					return errors.New("stdin is not a terminal: pass --yes to apply the fixes")
+				}
+				fmt.Printf("Apply %d fixes? [y/N]: ", fixable)
+				var answer string
+				fmt.Scanln(&answer)
+				if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+					return errors.New("no changes made")
+				}
+			}
+
+			fixed, backup, err := report.Fix()
+			if backup != "" {
+				fmt.Printf("Saved a backup of the store to %s\n", backup)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Fixed %d problems\n", fixed)
+			if remaining := len(report.Problems) - fixed; remaining > 0 {
+				return fmt.Errorf("%d problems need fixing by hand", remaining)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Apply the safe fixes")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask before applying fixes")
+
+	return cmd
+}
+
+func newStoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "store",
+		Short:        "Export, import and maintain the credential store",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newStoreExportCmd())
+	cmd.AddCommand(newStoreImportCmd())
+	cmd.AddCommand(newStoreDoctorCmd())
+
+	return cmd
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/cmd/ssh"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/config"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/gitsync"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// resolveConflicts settles each conflict by prefer, or by asking when prefer is empty
+func resolveConflicts(merge *credential.SyncMerge, prefer string) error {
+	if len(merge.Conflicts) == 0 {
+		return nil
+	}
+
+	if prefer == "" && !term.IsTerminal(int(os.Stdin.Fd())) {
+		lines := make([]string, len(merge.Conflicts))
+		for i, c := range merge.Conflicts {
+			lines[i] = "  " + c.String()
+		}
+		return fmt.Errorf("%d conflicts need resolving, run interactively or pass --prefer local|remote:\n%s", len(merge.Conflicts), strings.Join(lines, "\n"))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, c := range merge.Conflicts {
+		choice := prefer
+		for choice == "" {
+			fmt.Printf("Conflict: %s\nKeep [l]ocal or [r]emote? ", c)
+			answer, _ := reader.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "l", "local":
+				choice = "local"
+			case "r", "remote":
+				choice = "remote"
+			}
+		}
+		if choice == "remote" {
+			merge.UseRemote(c)
+		}
+	}
+	return nil
+}
+
+func printSyncChanges(label string, names []string) {
+	if len(names) > 0 {
+		fmt.Printf("%s: %s\n", label, strings.Join(names, ", "))
+	}
+}
+
+func newSyncCmd() *cobra.Command {
+	var (
+		remote string
+		prefer string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Share host entries with a team through a git repository",
+		Long: "Pull host entries from a git repository, merge them with the local store and push the result.\n" +
+			"Only the ID, name, host, port, user and tags are shared. Passwords, keys and other local settings never leave this machine.\n" +
+			"Entries are matched by ID and merged field by field; changes made on both sides since the last sync are conflicts to resolve.",
+		Example:      "  ssh-cli sync --remote git@github.com:team/hosts.git\n  ssh-cli sync --prefer remote",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if prefer != "" && prefer != "local" && prefer != "remote" {
+				return fmt.Errorf("invalid --prefer %q: use local or remote", prefer)
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if remote == "" {
+				remote = cfg.SyncRemote
+			}
+			if remote == "" {
+				return fmt.Errorf("no sync repository: pass --remote or run 'ssh-cli config set sync_remote <url>'")
+			}
+			if cfg.SyncRemote == "" {
+				// Remember the first repository used so later syncs need no flag
+				if file, err := config.LoadFile(); err == nil && file.Set("sync_remote", remote) == nil {
+					if err := file.Save(); err != nil {
+						fmt.Fprintf(os.Stderr, "warning: failed to remember sync repository: %v\n", err)
+					}
+				}
+			}
+
+			store, err := credential.NewCredentialStore()
+			if err != nil {
+				return fmt.Errorf("failed to initialize credential store: %w", err)
+			}
+
+			repo, err := gitsync.Open(store.SyncDir(), remote)
+			if err != nil {
+				return err
+			}
+			remoteEntries, err := repo.Pull()
+			if err != nil {
+				return err
+			}
+			base, err := store.LoadSyncBase(remote)
+			if err != nil {
+				return err
+			}
+
+			merge := credential.MergeShared(base, store.SharedCredentials(), remoteEntries)
+			if err := resolveConflicts(merge, prefer); err != nil {
+				return err
+			}
+			result := merge.Result()
+			renamed := credential.UniqueSharedNames(result)
+
+			// Push before touching the local store: if the remote moved on in
+			// the meantime nothing has changed locally and the next sync
+			// starts over from the same base
+			hostname, _ := os.Hostname()
+			pushed, err := repo.Push(result, fmt.Sprintf("Sync %d entries from %s", len(result), hostname))
+			if err != nil {
+				return err
+			}
+
+			// Entries new to this machine use the default key until updated
+			template := credential.SSHCredential{AuthType: credential.KeyFile, KeyPath: ssh.DefaultKeyPath()}
+			changes, err := store.ApplySync(result, template)
+			if err != nil {
+				return fmt.Errorf("failed to update credential store: %w", err)
+			}
+			if err := store.SaveSyncBase(remote, result); err != nil {
+				return err
+			}
+
+			printSyncChanges("Added", changes.Added)
+			printSyncChanges("Updated", changes.Updated)
+			printSyncChanges("Moved to trash", changes.Removed)
+			printSyncChanges("Renamed to avoid duplicate names", renamed)
+			if pushed {
+				fmt.Printf("Pushed %d entries to %s\n", len(result), remote)
+			} else {
+				fmt.Println("Remote is up to date.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&remote, "remote", "", "Git repository to sync with (remembered as sync_remote)")
+	cmd.Flags().StringVar(&prefer, "prefer", "", "Resolve all conflicts without asking (local/remote)")
+
+	return cmd
+}
@@ -0,0 +1,255 @@
+// Package ansible converts credentials to and from Ansible inventories.
+package ansible
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+	"gopkg.in/yaml.v3"
+)
+
+// Inventory formats
+const (
+	FormatINI  = "ini"
+	FormatYAML = "yaml"
+)
+
+// Host is one inventory host with its connection variables and groups
+type Host struct {
+	Name   string
+	Vars   map[string]any
+	Groups []string
+}
+
+// Inventory is a set of hosts sorted by name
+type Inventory struct {
+	Hosts []Host
+}
+
+// GroupName turns a tag into a valid Ansible group name: env=prod becomes
+// env_prod and a tag without a value keeps its key. Names of Ansible's
+// built-in groups all and ungrouped get a leading underscore, like names
+// starting with a digit.
+func GroupName(key, value string) string {
+	name := key
+	if value != "" {
+		name += "_" + value
+	}
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	group := b.String()
+	if group != "" && group[0] >= '0' && group[0] <= '9' || group == "all" || group == "ungrouped" {
+		group = "_" + group
+	}
+	return group
+}
+
+// FromCredentials builds an inventory with one host per credential, grouped
+// by tags. Passwords are never written.
+func FromCredentials(creds []credential.SSHCredential) Inventory {
+	var inv Inventory
+	for _, cred := range creds {
+		host := Host{
+			Name: cred.Name,
+			Vars: map[string]any{
+				"ansible_host": cred.Host,
+				"ansible_port": cred.Port,
+				"ansible_user": cred.Username,
+			},
+		}
+		if cred.AuthType == credential.KeyFile && cred.KeyPath != "" {
+			host.Vars["ansible_ssh_private_key_file"] = cred.KeyPath
+		}
+		if args := credential.SSHOptionArgs(cred.SSHOptions); len(args) > 0 {
+			for i, arg := range args {
+				args[i] = shellQuote(arg)
+			}
+			host.Vars["ansible_ssh_common_args"] = strings.Join(args, " ")
+		}
+		for key, value := range cred.Tags {
+			if group := GroupName(key, value); group != "" {
+				host.Groups = append(host.Groups, group)
+			}
+		}
+		sort.Strings(host.Groups)
+		inv.Hosts = append(inv.Hosts, host)
+	}
+	sort.Slice(inv.Hosts, func(i, j int) bool { return inv.Hosts[i].Name < inv.Hosts[j].Name })
+	return inv
+}
+
+// Groups returns the host names in each group. Hosts without a group are in
+// ungrouped.
+func (inv Inventory) Groups() map[string][]string {
+	groups := make(map[string][]string)
+	for _, host := range inv.Hosts {
+		if len(host.Groups) == 0 {
+			groups["ungrouped"] = append(groups["ungrouped"], host.Name)
+		}
+		for _, group := range host.Groups {
+			groups[group] = append(groups[group], host.Name)
+		}
+	}
+	return groups
+}
+
+// groupNames returns the group names sorted, with ungrouped first
+func groupNames(groups map[string][]string) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "ungrouped") != (names[j] == "ungrouped") {
+			return names[i] == "ungrouped"
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Host returns the named host
+func (inv Inventory) Host(name string) (Host, bool) {
+	for _, host := range inv.Hosts {
+		if host.Name == name {
+			return host, true
+		}
+	}
+	return Host{}, false
+}
+
+// Write writes the inventory as a static INI or YAML file. Each host's
+// variables appear once, where the host is first listed.
+func (inv Inventory) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatINI:
+		return inv.writeINI(w)
+	case FormatYAML:
+		return inv.writeYAML(w)
+	}
+	return fmt.Errorf("invalid inventory format %q: use ini or yaml", format)
+}
+
+func (inv Inventory) writeINI(w io.Writer) error {
+	var b bytes.Buffer
+	written := make(map[string]bool)
+	groups := inv.Groups()
+	for i, group := range groupNames(groups) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "[%s]\n", group)
+		for _, name := range groups[group] {
+			b.WriteString(name)
+			if !written[name] {
+				host, _ := inv.Host(name)
+				for _, key := range sortedKeys(host.Vars) {
+					fmt.Fprintf(&b, " %s=%s", key, iniValue(host.Vars[key]))
+				}
+				written[name] = true
+			}
+			b.WriteByte('\n')
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func (inv Inventory) writeYAML(w io.Writer) error {
+	written := make(map[string]bool)
+	children := make(map[string]any)
+	groups := inv.Groups()
+	for _, group := range groupNames(groups) {
+		hosts := make(map[string]any)
+		for _, name := range groups[group] {
+			if written[name] {
+				hosts[name] = map[string]any{}
+				continue
+			}
+			host, _ := inv.Host(name)
+			hosts[name] = host.Vars
+			written[name] = true
+		}
+		children[group] = map[string]any{"hosts": hosts}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]any{"all": map[string]any{"children": children}}); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// WriteList writes the JSON that Ansible expects from a dynamic inventory
+// script called with --list
+func (inv Inventory) WriteList(w io.Writer) error {
+	groups := inv.Groups()
+	list := map[string]any{}
+	hostvars := make(map[string]any, len(inv.Hosts))
+	for _, host := range inv.Hosts {
+		hostvars[host.Name] = host.Vars
+	}
+	list["_meta"] = map[string]any{"hostvars": hostvars}
+	names := groupNames(groups)
+	list["all"] = map[string]any{"children": names}
+	for _, group := range names {
+		list[group] = map[string]any{"hosts": groups[group]}
+	}
+	return writeJSON(w, list)
+}
+
+// WriteHost writes the variables of one host, as a dynamic inventory script
+// called with --host. Unknown hosts have no variables.
+func (inv Inventory) WriteHost(w io.Writer, name string) error {
+	host, _ := inv.Host(name)
+	if host.Vars == nil {
+		host.Vars = map[string]any{}
+	}
+	return writeJSON(w, host.Vars)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// iniValue quotes a variable value when Ansible's INI parser would split it
+func iniValue(v any) string {
+	s := fmt.Sprint(v)
+	if s != "" && !strings.ContainsAny(s, " \t\"'#;=\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// shellQuote quotes a word for ansible_ssh_common_args, which is split like
+// a shell command line
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$`") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,337 @@
+package ansible
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inventory is a parsed inventory before group variables are applied
+type inventory struct {
+	hosts      []string
+	hostVars   map[string]map[string]any
+	hostGroups map[string][]string
+	groupVars  map[string]map[string]any
+	parents    map[string][]string
+}
+
+func newInventory() *inventory {
+	return &inventory{
+		hostVars:   make(map[string]map[string]any),
+		hostGroups: make(map[string][]string),
+		groupVars:  make(map[string]map[string]any),
+		parents:    make(map[string][]string),
+	}
+}
+
+func (p *inventory) addHost(group, name string, vars map[string]any) {
+	if _, seen := p.hostVars[name]; !seen {
+		p.hosts = append(p.hosts, name)
+		p.hostVars[name] = make(map[string]any)
+	}
+	for key, value := range vars {
+		p.hostVars[name][key] = value
+	}
+	if group != "" && group != "all" && group != "ungrouped" && !contains(p.hostGroups[name], group) {
+		p.hostGroups[name] = append(p.hostGroups[name], group)
+	}
+}
+
+func (p *inventory) addGroupVars(group string, vars map[string]any) {
+	if p.groupVars[group] == nil {
+		p.groupVars[group] = make(map[string]any)
+	}
+	for key, value := range vars {
+		p.groupVars[group][key] = value
+	}
+}
+
+func (p *inventory) addChild(parent, child string) {
+	if !contains(p.parents[child], parent) {
+		p.parents[child] = append(p.parents[child], parent)
+	}
+}
+
+// resolve applies variables from all, then from each group with its parents
+// before it, then the host's own. Hosts belong to every ancestor group too.
+func (p *inventory) resolve() Inventory {
+	var inv Inventory
+	for _, name := range p.hosts {
+		host := Host{Name: name, Vars: make(map[string]any)}
+		for key, value := range p.groupVars["all"] {
+			host.Vars[key] = value
+		}
+
+		groups := make(map[string]bool)
+		var visit func(group string)
+		visit = func(group string) {
+			if groups[group] || group == "all" || group == "ungrouped" {
+				return
+			}
+			groups[group] = true
+			for _, parent := range p.parents[group] {
+				visit(parent)
+			}
+			for key, value := range p.groupVars[group] {
+				host.Vars[key] = value
+			}
+		}
+		direct := append([]string(nil), p.hostGroups[name]...)
+		sort.Strings(direct)
+		for _, group := range direct {
+			visit(group)
+		}
+
+		for key, value := range p.hostVars[name] {
+			host.Vars[key] = value
+		}
+		for group := range groups {
+			host.Groups = append(host.Groups, group)
+		}
+		sort.Strings(host.Groups)
+		inv.Hosts = append(inv.Hosts, host)
+	}
+	return inv
+}
+
+// Parse reads a static inventory in INI or YAML form. Variables inherited
+// from groups are applied to each host.
+func Parse(data []byte) (Inventory, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err == nil && len(root.Content) == 1 && root.Content[0].Kind == yaml.MappingNode {
+		return parseYAML(root.Content[0])
+	}
+	return parseINI(data)
+}
+
+func parseYAML(root *yaml.Node) (Inventory, error) {
+	p := newInventory()
+	var groups map[string]yamlGroup
+	if err := root.Decode(&groups); err != nil {
+		return Inventory{}, fmt.Errorf("invalid YAML inventory: %w", err)
+	}
+	for name, group := range groups {
+		p.addYAMLGroup(name, group)
+	}
+	inv := p.resolve()
+	if len(inv.Hosts) == 0 {
+		return Inventory{}, fmt.Errorf("no hosts found in inventory")
+	}
+	return inv, nil
+}
+
+type yamlGroup struct {
+	Hosts    map[string]map[string]any `yaml:"hosts"`
+	Vars     map[string]any            `yaml:"vars"`
+	Children map[string]*yamlGroup     `yaml:"children"`
+}
+
+func (p *inventory) addYAMLGroup(name string, group yamlGroup) {
+	p.addGroupVars(name, group.Vars)
+	hosts := make([]string, 0, len(group.Hosts))
+	for host := range group.Hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		p.addHost(name, host, group.Hosts[host])
+	}
+	for childName, child := range group.Children {
+		p.addChild(name, childName)
+		if child != nil {
+			p.addYAMLGroup(childName, *child)
+		}
+	}
+}
+
+func parseINI(data []byte) (Inventory, error) {
+	p := newInventory()
+	section, kind := "ungrouped", "hosts"
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, kind, _ = strings.Cut(line[1:len(line)-1], ":")
+			if kind == "" {
+				kind = "hosts"
+			}
+			if kind != "hosts" && kind != "vars" && kind != "children" {
+				return Inventory{}, fmt.Errorf("line %d: unknown section type %q", n, kind)
+			}
+			continue
+		}
+
+		fields, err := splitFields(line)
+		if err != nil {
+			return Inventory{}, fmt.Errorf("line %d: %w", n, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch kind {
+		case "children":
+			p.addChild(section, fields[0])
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return Inventory{}, fmt.Errorf("line %d: expected key=value", n)
+			}
+			p.addGroupVars(section, map[string]any{strings.TrimSpace(key): unquote(strings.TrimSpace(value))})
+		default:
+			vars := make(map[string]any)
+			for _, field := range fields[1:] {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					return Inventory{}, fmt.Errorf("line %d: expected key=value, got %q", n, field)
+				}
+				vars[key] = value
+			}
+			name := fields[0]
+			// host:port is shorthand for ansible_port
+			if host, port, ok := strings.Cut(name, ":"); ok && !strings.Contains(port, ":") && !strings.Contains(name, "[") {
+				if _, err := strconv.Atoi(port); err == nil {
+					name = host
+					if _, set := vars["ansible_port"]; !set {
+						vars["ansible_port"] = port
+					}
+				}
+			}
+			names, err := expandRange(name)
+			if err != nil {
+				return Inventory{}, fmt.Errorf("line %d: %w", n, err)
+			}
+			for _, host := range names {
+				p.addHost(section, host, vars)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Inventory{}, err
+	}
+
+	inv := p.resolve()
+	if len(inv.Hosts) == 0 {
+		return Inventory{}, fmt.Errorf("no hosts found in inventory")
+	}
+	return inv, nil
+}
+
+// expandRange expands a numeric host pattern such as web[01:03] into
+// web01, web02 and web03
+func expandRange(name string) ([]string, error) {
+	start := strings.Index(name, "[")
+	if start == -1 {
+		return []string{name}, nil
+	}
+	end := strings.Index(name[start:], "]")
+	if end == -1 {
+		return nil, fmt.Errorf("unclosed range in %q", name)
+	}
+	end += start
+	prefix, spec, suffix := name[:start], name[start+1:end], name[end+1:]
+
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid range in %q", name)
+	}
+	from := parts[0]
+	first, err1 := strconv.Atoi(from)
+	last, err2 := strconv.Atoi(parts[1])
+	step := 1
+	if len(parts) == 3 {
+		var err error
+		if step, err = strconv.Atoi(parts[2]); err != nil || step <= 0 {
+			return nil, fmt.Errorf("invalid range step in %q", name)
+		}
+	}
+	if err1 != nil || err2 != nil || last < first {
+		return nil, fmt.Errorf("only numeric ranges such as [1:3] are supported, got %q", name)
+	}
+
+	width := 0
+	if len(from) > 1 && from[0] == '0' {
+		width = len(from)
+	}
+	var names []string
+	for i := first; i <= last; i += step {
+		rest, err := expandRange(suffix)
+		if err != nil {
+			return nil, err
+		}
+		for _, tail := range rest {
+			names = append(names, fmt.Sprintf("%s%0*d%s", prefix, width, i, tail))
+		}
+	}
+	return names, nil
+}
+
+// splitFields splits an INI host line on whitespace, honouring quotes and
+// dropping a trailing comment
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	var b strings.Builder
+	var quote rune
+	inField := false
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			switch {
+			case r == quote:
+				quote = 0
+			case r == '\\' && quote == '"':
+				escaped = true
+			default:
+				b.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, b.String())
+				b.Reset()
+				inField = false
+			}
+		case r == '#' && !inField:
+			return fields, nil
+		default:
+			b.WriteRune(r)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, b.String())
+	}
+	return fields, nil
+}
+
+func unquote(value string) string {
+	if fields, err := splitFields(value); err == nil && len(fields) == 1 {
+		return fields[0]
+	}
+	return value
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
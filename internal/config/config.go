@@ -0,0 +1,253 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats understood by commands that print credentials
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// DefaultTrashRetentionDays is how long deleted credentials are kept when
+// trash_retention_days is not set
+const DefaultTrashRetentionDays = 30
+
+// Config holds user defaults. Values come from the config file and can be
+// overridden by SSH_CLI_* environment variables, which command line flags
+// override in turn.
+type Config struct {
+	User       string            `yaml:"user,omitempty"`
+	Key        string            `yaml:"key,omitempty"`
+	Port       int               `yaml:"port,omitempty"`
+	SSHBinary  string            `yaml:"ssh_binary,omitempty"`
+	Output     string            `yaml:"output,omitempty"`
+	SSHOptions map[string]string `yaml:"ssh_options,omitempty"`
+	SyncRemote string            `yaml:"sync_remote,omitempty"`
+
+	// TrashRetentionDays is how many days deleted credentials stay in the
+	// trash before being purged
+	TrashRetentionDays int `yaml:"trash_retention_days,omitempty"`
+
+	// AllowInsecurePermissions turns refusing a store or key file that other
+	// users can read into a warning
+	AllowInsecurePermissions bool `yaml:"allow_insecure_permissions,omitempty"`
+
+	path string
+}
+
+// Keys lists the settings that can be read and written with Get and Set.
+// ssh options are addressed as ssh_options.<Name>.
+var Keys = []string{"user", "key", "port", "ssh_binary", "output", "ssh_options", "sync_remote", "trash_retention_days", "allow_insecure_permissions"}
+
+var envOverrides = map[string]string{
+	"user":        "SSH_CLI_USER",
+	"key":         "SSH_CLI_KEY",
+	"port":        "SSH_CLI_PORT",
+	"ssh_binary":  "SSH_CLI_SSH_BINARY",
+	"output":      "SSH_CLI_OUTPUT",
+	"sync_remote": "SSH_CLI_SYNC_REMOTE",
+
+	"trash_retention_days":       "SSH_CLI_TRASH_RETENTION_DAYS",
+	"allow_insecure_permissions": "SSH_CLI_ALLOW_INSECURE_PERMISSIONS",
+}
+
+// Path returns the config file location, following the XDG base directory spec
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(dir, "ssh-cli", "config.yaml"), nil
+}
+
+// LoadFile reads the config file only, without environment overrides or
+// defaults. A missing file is an empty config.
+func LoadFile() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Load returns the effective config: the file, then environment overrides, then
+// built-in defaults for anything still unset
+func Load() (*Config, error) {
+	cfg, err := LoadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	for key, env := range envOverrides {
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			if err := cfg.Set(key, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", env, err)
+			}
+		}
+	}
+
+	if cfg.Port == 0 {
+		cfg.Port = 22
+	}
+	if cfg.SSHBinary == "" {
+		cfg.SSHBinary = "ssh"
+	}
+	if cfg.Output == "" {
+		cfg.Output = OutputText
+	}
+	if cfg.TrashRetentionDays == 0 {
+		cfg.TrashRetentionDays = DefaultTrashRetentionDays
+	}
+	return cfg, nil
+}
+
+// Save writes the config file, creating its directory if needed
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// Get returns a setting as a string
+func (c *Config) Get(key string) (string, error) {
+	if name, ok := strings.CutPrefix(key, "ssh_options."); ok {
+		return c.SSHOptions[name], nil
+	}
+
+	switch key {
+	case "user":
+		return c.User, nil
+	case "key":
+		return c.Key, nil
+	case "port":
+		if c.Port == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(c.Port), nil
+	case "ssh_binary":
+		return c.SSHBinary, nil
+	case "output":
+		return c.Output, nil
+	case "sync_remote":
+		return c.SyncRemote, nil
+	case "trash_retention_days":
+		if c.TrashRetentionDays == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(c.TrashRetentionDays), nil
+	case "allow_insecure_permissions":
+		if !c.AllowInsecurePermissions {
+			return "", nil
+		}
+		return "true", nil
+	case "ssh_options":
+		pairs := make([]string, 0, len(c.SSHOptions))
+		for name, value := range c.SSHOptions {
+			pairs = append(pairs, name+"="+value)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ", "), nil
+	}
+	return "", fmt.Errorf("unknown config key %q: use one of %s or ssh_options.<Name>", key, strings.Join(Keys, ", "))
+}
+
+// Set changes a setting from its string form. An empty value clears it.
+func (c *Config) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+	if name, ok := strings.CutPrefix(key, "ssh_options."); ok {
+		if name == "" {
+			return errors.New("missing ssh option name")
+		}
+		if value == "" {
+			delete(c.SSHOptions, name)
+			return nil
+		}
+		if c.SSHOptions == nil {
+			c.SSHOptions = make(map[string]string)
+		}
+		c.SSHOptions[name] = value
+		return nil
+	}
+
+	switch key {
+	case "user":
+		c.User = value
+	case "key":
+		c.Key = value
+	case "port":
+		if value == "" {
+			c.Port = 0
+			return nil
+		}
+		port, err := strconv.Atoi(value)
+		if err != nil || port <= 0 || port > 65535 {
+			return errors.New("port must be between 1 and 65535")
+		}
+		c.Port = port
+	case "ssh_binary":
+		c.SSHBinary = value
+	case "output":
+		if value != "" && value != OutputText && value != OutputJSON {
+			return fmt.Errorf("invalid output format %q: use text or json", value)
+		}
+		c.Output = value
+	case "sync_remote":
+		c.SyncRemote = value
+	case "trash_retention_days":
+		if value == "" {
+			c.TrashRetentionDays = 0
+			return nil
+		}
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			return errors.New("trash retention must be a positive number of days")
+		}
+		c.TrashRetentionDays = days
+	case "allow_insecure_permissions":
+		if value == "" {
+			c.AllowInsecurePermissions = false
+			return nil
+		}
+		allow, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q: use true or false", value)
+		}
+		c.AllowInsecurePermissions = allow
+	case "ssh_options":
+		return errors.New("set ssh options one at a time with ssh_options.<Name>")
+	default:
+		return fmt.Errorf("unknown config key %q: use one of %s or ssh_options.<Name>", key, strings.Join(Keys, ", "))
+	}
+	return nil
+}
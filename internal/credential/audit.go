@@ -0,0 +1,187 @@
+package credential
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditSave    AuditAction = "save"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+	AuditConnect AuditAction = "connect"
+)
+
+// AuditEntry is a single line of the audit log. It never contains secret values.
+type AuditEntry struct {
+	Time         time.Time   `json:"time"`
+	Action       AuditAction `json:"action"`
+	User         string      `json:"user"`
+	Credential   string      `json:"credential"`
+	CredentialID string      `json:"credential_id,omitempty"`
+	Host         string      `json:"host,omitempty"`
+	Fields       []string    `json:"fields,omitempty"`
+	StartedAt    *time.Time  `json:"started_at,omitempty"`
+	EndedAt      *time.Time  `json:"ended_at,omitempty"`
+	ExitCode     *int        `json:"exit_code,omitempty"`
+	Recording    string      `json:"recording,omitempty"`
+}
+
+// AuditFilter selects entries from the audit log. Zero values match everything.
+type AuditFilter struct {
+	Credential string
+	Action     AuditAction
+	Since      time.Time
+	Until      time.Time
+}
+
+func (f AuditFilter) matches(e AuditEntry) bool {
+	if f.Credential != "" && e.Credential != f.Credential && e.CredentialID != f.Credential {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+func (s *CredentialStore) auditPath() string {
+	return filepath.Join(filepath.Dir(s.filepath), "audit.log")
+}
+
+// SessionDir is where recordings of SSH sessions are kept
+func (s *CredentialStore) SessionDir() string {
+	return filepath.Join(filepath.Dir(s.filepath), "sessions")
+}
+
+// Audit appends an entry to the audit log, filling in the time and user if unset
+func (s *CredentialStore) Audit(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.User == "" {
+		entry.User = currentUser()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.auditPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// AuditConnection records a finished SSH session for a credential, with the
+// path of its recording if it was recorded
+func (s *CredentialStore) AuditConnection(cred SSHCredential, start, end time.Time, exitCode int, recording string) error {
+	return s.Audit(AuditEntry{
+		Time:         end,
+		Action:       AuditConnect,
+		Credential:   cred.Name,
+		CredentialID: cred.ID,
+		Host:         fmt.Sprintf("%s@%s:%d", cred.Username, cred.Host, cred.Port),
+		StartedAt:    &start,
+		EndedAt:      &end,
+		ExitCode:     &exitCode,
+		Recording:    recording,
+	})
+}
+
+// ReadAuditLog returns the audit entries matching the filter, oldest first
+func (s *CredentialStore) ReadAuditLog(filter AuditFilter) ([]AuditEntry, error) {
+	f, err := os.Open(s.auditPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit log entry on line %d: %w", line, err)
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// auditChange records a change that has already been saved. Failing to
+// write the entry does not undo the change, so it is only reported as a
+// warning, like a failed connection entry.
+func (s *CredentialStore) auditChange(action AuditAction, cred SSHCredential, fields []string) {
+	err := s.Audit(AuditEntry{
+		Action:       action,
+		Credential:   cred.Name,
+		CredentialID: cred.ID,
+		Host:         fmt.Sprintf("%s@%s:%d", cred.Username, cred.Host, cred.Port),
+		Fields:       fields,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// ChangedFields returns the JSON names of the fields that differ between two credentials,
+// ignoring bookkeeping timestamps and usage history
+func ChangedFields(old, updated SSHCredential) []string {
+	var fields []string
+	ov := reflect.ValueOf(old)
+	uv := reflect.ValueOf(updated)
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		switch name {
+		case "", "-", "created_at", "updated_at", "deleted_at", "last_used_at", "use_count":
+			continue
+		}
+		if !reflect.DeepEqual(ov.Field(i).Interface(), uv.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,134 @@
+package credential
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle formats
+const (
+	BundleJSON = "json"
+	BundleYAML = "yaml"
+)
+
+// BundleVersion is the version of the bundle format written by Export
+const BundleVersion = 1
+
+// BundleEntry is a credential in a bundle, with the private key itself when
+// secrets are included
+type BundleEntry struct {
+	SSHCredential
+	KeyData string `json:"key_data,omitempty"`
+}
+
+// Bundle is a portable copy of the credential store
+type Bundle struct {
+	Version     int           `json:"version"`
+	ExportedAt  time.Time     `json:"exported_at"`
+	Credentials []BundleEntry `json:"credentials"`
+}
+
+// Export copies all credentials into a bundle. Without includeSecrets stored
+// passwords are left out; with it, passwords held in the local keyring are
+// resolved and private keys are embedded. References to vaults, files and
+// environment variables are kept as they are. The returned warnings name
+// entries that will need attention after import.
+func (s *CredentialStore) Export(includeSecrets bool) (*Bundle, []string, error) {
+	bundle := &Bundle{Version: BundleVersion, ExportedAt: time.Now().UTC()}
+	var warnings []string
+
+	for _, cred := range s.Credentials {
+		entry := BundleEntry{SSHCredential: cred}
+		entry.LastUsedAt = nil
+		entry.UseCount = 0
+
+		keyringPassword := strings.HasPrefix(cred.PasswordRef, KeyringScheme)
+		switch {
+		case !includeSecrets:
+			entry.Password = ""
+			if keyringPassword {
+				entry.PasswordRef = ""
+			}
+			if cred.AuthType == Password && entry.PasswordRef == "" {
+				warnings = append(warnings, fmt.Sprintf("%s: password not exported", cred.Name))
+			}
+		case keyringPassword:
+			password, err := cred.ResolvePassword()
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", cred.Name, err)
+			}
+			entry.Password = password
+			entry.PasswordRef = ""
+		}
+
+		if includeSecrets && cred.AuthType == KeyFile && cred.KeyRef == "" && cred.KeyPath != "" {
+			data, err := os.ReadFile(cred.KeyPath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: failed to read key: %w", cred.Name, err)
+			}
+			entry.KeyData = string(data)
+		}
+		if strings.HasPrefix(cred.KeyPassRef, KeyringScheme) {
+			warnings = append(warnings, fmt.Sprintf("%s: key passphrase is in the local keyring and was not exported", cred.Name))
+		}
+
+		bundle.Credentials = append(bundle.Credentials, entry)
+	}
+	return bundle, warnings, nil
+}
+
+// EncodeBundle writes a bundle as JSON or YAML. YAML uses the same field names
+// as the JSON store.
+func EncodeBundle(bundle *Bundle, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(bundle, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case BundleJSON:
+		return append(data, '\n'), nil
+	case BundleYAML:
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(generic)
+	}
+	return nil, fmt.Errorf("invalid format %q: use json or yaml", format)
+}
+
+// DecodeBundle reads a JSON or YAML bundle. A plain credentials.json store is
+// accepted too.
+func DecodeBundle(data []byte) (*Bundle, error) {
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		var generic any
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		var err error
+		if trimmed, err = json.Marshal(generic); err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(trimmed, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than this version of ssh-cli supports", bundle.Version)
+	}
+	if len(bundle.Credentials) == 0 {
+		return nil, errors.New("bundle contains no credentials")
+	}
+	return &bundle, nil
+}
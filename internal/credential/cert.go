@@ -0,0 +1,139 @@
+package credential
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// CertExpiryWarning is how close to expiry a certificate has to be before it is flagged
+const CertExpiryWarning = 24 * time.Hour
+
+// SignOptions describes a user certificate to issue
+type SignOptions struct {
+	CA         ssh.Signer
+	PublicKey  ssh.PublicKey
+	KeyID      string
+	Principals []string
+	Validity   time.Duration
+	Serial     uint64
+}
+
+// LoadCertificate reads an OpenSSH certificate such as id_ed25519-cert.pub
+func LoadCertificate(path string) (*ssh.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %w", path, err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is a public key, not a certificate", path)
+	}
+	return cert, nil
+}
+
+// LoadPublicKey reads an OpenSSH public key. A private key path is accepted too,
+// in which case the matching .pub file next to it is used.
+func LoadPublicKey(path string) (ssh.PublicKey, string, error) {
+	if !strings.HasSuffix(path, ".pub") {
+		if _, err := os.Stat(path + ".pub"); err == nil {
+			path += ".pub"
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	if _, ok := pub.(*ssh.Certificate); ok {
+		return nil, "", fmt.Errorf("%s is already a certificate", path)
+	}
+	return pub, path, nil
+}
+
+// CertPathFor returns where OpenSSH looks for the certificate of a key: id_ed25519 -> id_ed25519-cert.pub
+func CertPathFor(keyPath string) string {
+	return strings.TrimSuffix(keyPath, ".pub") + "-cert.pub"
+}
+
+// SignUserKey issues a user certificate signed by the CA and returns it in authorized_keys format
+func SignUserKey(opts SignOptions) ([]byte, error) {
+	if opts.CA == nil || opts.PublicKey == nil {
+		return nil, errors.New("a CA key and a public key are required")
+	}
+	if len(opts.Principals) == 0 {
+		return nil, errors.New("at least one principal is required")
+	}
+	if opts.Validity <= 0 {
+		return nil, errors.New("validity must be positive")
+	}
+
+	now := time.Now()
+	cert := &ssh.Certificate{
+		Key:             opts.PublicKey,
+		Serial:          opts.Serial,
+		CertType:        ssh.UserCert,
+		KeyId:           opts.KeyID,
+		ValidPrincipals: opts.Principals,
+		// Allow for small clock differences between this machine and the servers
+		ValidAfter:  uint64(now.Add(-5 * time.Minute).Unix()),
+		ValidBefore: uint64(now.Add(opts.Validity).Unix()),
+		Permissions: ssh.Permissions{
+			Extensions: map[string]string{
+				"permit-X11-forwarding":   "",
+				"permit-agent-forwarding": "",
+				"permit-port-forwarding":  "",
+				"permit-pty":              "",
+				"permit-user-rc":          "",
+			},
+		},
+	}
+	if err := cert.SignCert(rand.Reader, opts.CA); err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	return ssh.MarshalAuthorizedKey(cert), nil
+}
+
+// CertExpiry returns when a certificate stops being valid
+func CertExpiry(cert *ssh.Certificate) time.Time {
+	if cert.ValidBefore == ssh.CertTimeInfinity {
+		return time.Time{}
+	}
+	return time.Unix(int64(cert.ValidBefore), 0)
+}
+
+// CertWarning describes a problem with the credential's certificate, or returns
+// an empty string when it is fine or there is none
+func (c *SSHCredential) CertWarning() string {
+	if c.CertPath == "" {
+		return ""
+	}
+	cert, err := LoadCertificate(c.CertPath)
+	if err != nil {
+		return err.Error()
+	}
+	expiry := CertExpiry(cert)
+	if expiry.IsZero() {
+		return ""
+	}
+	remaining := time.Until(expiry)
+	switch {
+	case remaining <= 0:
+		return fmt.Sprintf("certificate expired %s ago", (-remaining).Round(time.Minute))
+	case remaining < CertExpiryWarning:
+		return fmt.Sprintf("certificate expires in %s", remaining.Round(time.Minute))
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package credential
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NormalizeHost returns the form of a host name used to spot duplicates:
+// lower case, without a trailing dot, and IP addresses in canonical form
+func NormalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+// duplicateKey identifies the account a credential logs in to
+func duplicateKey(cred SSHCredential, host string) string {
+	return fmt.Sprintf("%s@%s:%d", cred.Username, host, cred.Port)
+}
+
+// Duplicates returns the other credentials for the same user, host and port
+func (s *CredentialStore) Duplicates(cred SSHCredential) []SSHCredential {
+	key := duplicateKey(cred, NormalizeHost(cred.Host))
+	var matches []SSHCredential
+	for _, existing := range s.Credentials {
+		if existing.Name != cred.Name && duplicateKey(existing, NormalizeHost(existing.Host)) == key {
+			matches = append(matches, existing)
+		}
+	}
+	return matches
+}
+
+// DuplicateGroups returns the credentials that share a user, host and port,
+// in groups of two or more ordered by name. With resolve set, host names
+// are looked up so that a name and its address count as the same host; hosts
+// that do not resolve are compared by name.
+func DuplicateGroups(creds []SSHCredential, resolve bool) [][]SSHCredential {
+	hosts := make(map[string]string)
+	hostKey := func(host string) string {
+		host = NormalizeHost(host)
+		if !resolve || net.ParseIP(host) != nil {
+			return host
+		}
+		if key, ok := hosts[host]; ok {
+			return key
+		}
+		key := host
+		if addrs, err := net.LookupHost(host); err == nil && len(addrs) > 0 {
+			for i, addr := range addrs {
+				addrs[i] = NormalizeHost(addr)
+			}
+			sort.Strings(addrs)
+			key = addrs[0]
+		}
+		hosts[host] = key
+		return key
+	}
+
+	byKey := make(map[string][]SSHCredential)
+	var keys []string
+	for _, cred := range creds {
+		key := duplicateKey(cred, hostKey(cred.Host))
+		if _, seen := byKey[key]; !seen {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], cred)
+	}
+
+	var groups [][]SSHCredential
+	for _, key := range keys {
+		if group := byKey[key]; len(group) > 1 {
+			sort.Slice(group, func(i, j int) bool { return group[i].Name < group[j].Name })
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0].Name < groups[j][0].Name })
+	return groups
+}
+
+// PreferredDuplicate returns the index of the credential to keep from a
+// group: the most used, then the most recently used, then the oldest
+func PreferredDuplicate(group []SSHCredential) int {
+	best := 0
+	for i, cred := range group[1:] {
+		b := group[best]
+		switch {
+		case cred.UseCount != b.UseCount:
+			if cred.UseCount > b.UseCount {
+				best = i + 1
+			}
+		case !lastUsed(cred).Equal(lastUsed(b)):
+			if lastUsed(cred).After(lastUsed(b)) {
+				best = i + 1
+			}
+		case cred.CreatedAt.Before(b.CreatedAt):
+			best = i + 1
+		}
+	}
+	return best
+}
+
+// MergeDuplicates folds other credentials into keep. Tags and ssh options
+// are combined with keep's values winning, empty fields are filled in, and
+// the usage history is added up.
+func MergeDuplicates(keep SSHCredential, others []SSHCredential) SSHCredential {
+	merged := keep
+	merged.Tags = mergeMaps(keep.Tags, others, func(c SSHCredential) map[string]string { return c.Tags })
+	merged.SSHOptions = mergeMaps(keep.SSHOptions, others, func(c SSHCredential) map[string]string { return c.SSHOptions })
+	for _, other := range others {
+		if len(merged.ExtraArgs) == 0 {
+			merged.ExtraArgs = other.ExtraArgs
+		}
+		if merged.RemoteCommand == "" {
+			merged.RemoteCommand = other.RemoteCommand
+		}
+		if merged.CertPath == "" && merged.AuthType == KeyFile && other.KeyPath == merged.KeyPath {
+			merged.CertPath = other.CertPath
+		}
+		merged.UseCount += other.UseCount
+		if lastUsed(other).After(lastUsed(merged)) {
+			merged.LastUsedAt = other.LastUsedAt
+		}
+		if other.CreatedAt.Before(merged.CreatedAt) {
+			merged.CreatedAt = other.CreatedAt
+		}
+	}
+	return merged
+}
+
+func mergeMaps(keep map[string]string, others []SSHCredential, field func(SSHCredential) map[string]string) map[string]string {
+	var merged map[string]string
+	for _, other := range others {
+		for key, value := range field(other) {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[key] = value
+		}
+	}
+	if merged == nil {
+		return keep
+	}
+	for key, value := range keep {
+		merged[key] = value
+	}
+	return merged
+}
+
+// MergeCredentials replaces the credential named keep with merged and moves
+// the credentials named in remove to the trash
+func (s *CredentialStore) MergeCredentials(keep string, merged SSHCredential, remove []string) error {
+	idx := -1
+	for i, cred := range s.Credentials {
+		if cred.Name == keep {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("credential not found: %s", keep)
+	}
+	old := s.Credentials[idx]
+	merged.UpdatedAt = time.Now()
+	s.Credentials[idx] = merged
+
+	now := time.Now()
+	var removed []SSHCredential
+	for _, name := range remove {
+		for i, cred := range s.Credentials {
+			if cred.Name == name && name != keep {
+				s.Credentials = append(s.Credentials[:i], s.Credentials[i+1:]...)
+				cred.DeletedAt = &now
+				s.Trash = append(s.Trash, cred)
+				removed = append(removed, cred)
+				break
+			}
+		}
+	}
+
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.auditChange(AuditUpdate, merged, ChangedFields(old, merged))
+	for _, cred := range removed {
+		s.auditChange(AuditDelete, cred, nil)
+	}
+	return nil
+}
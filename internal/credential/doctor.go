@@ -0,0 +1,337 @@
+package credential
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DoctorProblem is one issue found in the store
+type DoctorProblem struct {
+	// Credential names the affected credential; empty for the store itself
+	Credential string
+	Message    string
+	// Fix describes the automatic repair, or is empty when it needs a person
+	Fix   string
+	apply func(*DoctorReport) error
+}
+
+func (p DoctorProblem) String() string {
+	line := p.Message
+	if p.Credential != "" {
+		line = p.Credential + ": " + line
+	}
+	if p.Fix != "" {
+		line += " (fix: " + p.Fix + ")"
+	}
+	return line
+}
+
+// DoctorReport is the result of checking the store
+type DoctorReport struct {
+	Path     string
+	Problems []DoctorProblem
+
+	store    *CredentialStore
+	original []SSHCredential
+	changed  map[int]bool
+}
+
+// Fixable returns how many problems can be repaired automatically
+func (r *DoctorReport) Fixable() int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.apply != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Doctor checks the store file without loading it through
+// NewCredentialStore, so that it works on a store every other command
+// refuses to open.
+func Doctor() (*DoctorReport, error) {
+	path, err := defaultStorePath()
+	if err != nil {
+		return nil, err
+	}
+	r := &DoctorReport{Path: path, changed: make(map[int]bool)}
+
+	r.checkMode(filepath.Dir(path), 0700, "store directory")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.checkMode(path, 0600, "store file")
+
+	store := &CredentialStore{filepath: path}
+	if err := json.Unmarshal(data, store); err != nil {
+		r.Problems = append(r.Problems, DoctorProblem{Message: jsonErrorMessage(data, err)})
+		return r, nil
+	}
+	r.store = store
+	r.original = append([]SSHCredential(nil), store.Credentials...)
+	r.checkCredentials()
+	return r, nil
+}
+
+// checkMode reports a file or directory that others can read or own
+func (r *DoctorReport) checkMode(path string, want fs.FileMode, what string) {
+	var permErr *PermissionError
+	if !errors.As(CheckPermissions(path), &permErr) {
+		return
+	}
+	if permErr.Readable {
+		r.Problems = append(r.Problems, DoctorProblem{
+			Message: fmt.Sprintf("%s %s has permissions %04o and can be read by other users", what, path, permErr.Mode),
+			Fix:     fmt.Sprintf("chmod %04o", want),
+			apply:   func(*DoctorReport) error { return os.Chmod(path, want) },
+		})
+	}
+	if permErr.Owner >= 0 {
+		r.Problems = append(r.Problems, DoctorProblem{
+			Message: fmt.Sprintf("%s %s is owned by another user (uid %d)", what, path, permErr.Owner),
+		})
+	}
+}
+
+// jsonErrorMessage turns a decoding error into a message with a line and column
+func jsonErrorMessage(data []byte, err error) string {
+	var offset int64 = -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+	if offset < 0 {
+		return fmt.Sprintf("credentials.json cannot be read: %v", err)
+	}
+	// Offsets count the bytes read, including the offending one
+	before := data[:min(max(int(offset)-1, 0), len(data))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("credentials.json line %d, column %d: %v; fix it by hand, nothing else can be checked until then", line, column, err)
+}
+
+// credentialLabel names a credential in a report, even one without a name
+func credentialLabel(i int, cred SSHCredential) string {
+	if cred.Name != "" {
+		return cred.Name
+	}
+	return fmt.Sprintf("credential #%d", i+1)
+}
+
+func (r *DoctorReport) add(i int, cred SSHCredential, message, fix string, apply func(*SSHCredential)) {
+	p := DoctorProblem{Credential: credentialLabel(i, cred), Message: message, Fix: fix}
+	if apply != nil {
+		p.apply = func(r *DoctorReport) error {
+			apply(&r.store.Credentials[i])
+			r.changed[i] = true
+			return nil
+		}
+	}
+	r.Problems = append(r.Problems, p)
+}
+
+func (r *DoctorReport) checkCredentials() {
+	creds := r.store.Credentials
+
+	names := make(map[string]bool)
+	for _, cred := range creds {
+		names[strings.ToLower(strings.TrimSpace(cred.Name))] = true
+	}
+	uniqueName := func(base string) string {
+		if base == "" {
+			base = "host"
+		}
+		for n := 2; ; n++ {
+			if candidate := fmt.Sprintf("%s-%d", base, n); !names[candidate] {
+				names[candidate] = true
+				return candidate
+			}
+		}
+	}
+
+	seenNames := make(map[string]bool)
+	seenIDs := make(map[string]bool)
+	for i, cred := range creds {
+		// Identity
+		switch {
+		case cred.ID == "":
+			r.add(i, cred, "missing ID", "generate one", func(c *SSHCredential) { c.ID, _ = GenerateID() })
+		case seenIDs[cred.ID]:
+			// Only live credentials count: a trashed copy of an entry that
+			// sync or import brought back keeps the same ID on purpose
+			r.add(i, cred, fmt.Sprintf("ID %s is also used by another credential", cred.ID), "generate a new one",
+				func(c *SSHCredential) { c.ID, _ = GenerateID() })
+		}
+		seenIDs[cred.ID] = true
+
+		name := strings.ToLower(strings.TrimSpace(cred.Name))
+		switch {
+		case name == "":
+			newName := strings.ToLower(cred.Host)
+			if newName == "" || names[newName] {
+				newName = uniqueName(newName)
+			}
+			names[newName] = true
+			r.add(i, cred, "missing name", "name it "+newName, func(c *SSHCredential) { c.Name = newName })
+			name = newName
+		case seenNames[name]:
+			newName := uniqueName(name)
+			r.add(i, cred, fmt.Sprintf("name %s is used by another credential", name), "rename to "+newName,
+				func(c *SSHCredential) { c.Name = newName })
+			name = newName
+		case name != cred.Name:
+			r.add(i, cred, "name is not in lower case or has surrounding spaces", "rename to "+name,
+				func(c *SSHCredential) { c.Name = name })
+		}
+		seenNames[name] = true
+
+		// Connection
+		if strings.TrimSpace(cred.Host) == "" {
+			r.add(i, cred, "missing host; set it with 'ssh-cli ssh update --host'", "", nil)
+		}
+		if strings.TrimSpace(cred.Username) == "" {
+			r.add(i, cred, "missing username; set it with 'ssh-cli ssh update --user'", "", nil)
+		}
+		switch {
+		case cred.Port == 0:
+			r.add(i, cred, "missing port", "use 22", func(c *SSHCredential) { c.Port = 22 })
+		case cred.Port < 0 || cred.Port > 65535:
+			r.add(i, cred, fmt.Sprintf("invalid port %d; set it with 'ssh-cli ssh update --port'", cred.Port), "", nil)
+		}
+
+		// Authentication
+		switch cred.AuthType {
+		case KeyFile, Password:
+		default:
+			guess := AuthType("")
+			switch {
+			case cred.KeyPath != "" || cred.KeyRef != "":
+				guess = KeyFile
+			case cred.Password != "" || cred.PasswordRef != "":
+				guess = Password
+			}
+			if guess == "" {
+				r.add(i, cred, fmt.Sprintf("invalid auth type %q and nothing to tell which one was meant", cred.AuthType), "", nil)
+				break
+			}
+			r.add(i, cred, fmt.Sprintf("invalid auth type %q", cred.AuthType), "set it to "+string(guess),
+				func(c *SSHCredential) { c.AuthType = guess })
+			cred.AuthType = guess
+		}
+		if cred.AuthType == KeyFile && cred.KeyRef == "" {
+			// Without a key path ssh uses its agent and config
+			if cred.KeyPath != "" {
+				if _, err := os.Stat(cred.KeyPath); errors.Is(err, fs.ErrNotExist) {
+					r.add(i, cred, fmt.Sprintf("key file %s does not exist", cred.KeyPath), "", nil)
+				}
+			}
+			if cred.Password != "" {
+				r.add(i, cred, "stores an unused plain-text password", "remove it", func(c *SSHCredential) { c.Password = "" })
+			}
+		}
+		if cred.AuthType == Password && cred.Password == "" && cred.PasswordRef == "" {
+			r.add(i, cred, "password authentication without a password; set one with 'ssh-cli ssh update'", "", nil)
+		}
+
+		// References to things outside the store
+		for _, ref := range []struct{ field, value string }{
+			{"password_ref", cred.PasswordRef},
+			{"key_ref", cred.KeyRef},
+			{"key_passphrase_ref", cred.KeyPassRef},
+		} {
+			if ref.value == "" {
+				continue
+			}
+			if err := ValidateSecretRef(ref.value); err != nil {
+				r.add(i, cred, fmt.Sprintf("%s: %v", ref.field, err), "", nil)
+				continue
+			}
+			if path, ok := secretFilePath(ref.value); ok {
+				if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+					r.add(i, cred, fmt.Sprintf("%s points at %s, which does not exist", ref.field, path), "", nil)
+				}
+			}
+		}
+		if cred.CertPath != "" {
+			if _, err := os.Stat(cred.CertPath); errors.Is(err, fs.ErrNotExist) {
+				r.add(i, cred, fmt.Sprintf("certificate %s does not exist", cred.CertPath), "stop using it",
+					func(c *SSHCredential) { c.CertPath = "" })
+			}
+		}
+		if file := cred.SSHOptions["UserKnownHostsFile"]; file != "" && file != "/dev/null" && !strings.Contains(file, "%") {
+			if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+				r.add(i, cred, fmt.Sprintf("UserKnownHostsFile %s does not exist, so ssh will ask about the host key again", file), "", nil)
+			}
+		}
+	}
+}
+
+// secretFilePath returns the file behind a file:// reference
+func secretFilePath(ref string) (string, bool) {
+	u, provider, err := parseSecretRef(ref)
+	if err != nil {
+		return "", false
+	}
+	fp, ok := provider.(fileProvider)
+	if !ok {
+		return "", false
+	}
+	path, err := fp.path(u)
+	return path, err == nil
+}
+
+// Fix applies every automatic repair. The store file is copied to a backup
+// first, and every changed credential is recorded in the audit log.
+func (r *DoctorReport) Fix() (fixed int, backup string, err error) {
+	if r.Fixable() == 0 {
+		return 0, "", nil
+	}
+	for _, p := range r.Problems {
+		if p.apply == nil {
+			continue
+		}
+		if err := p.apply(r); err != nil {
+			return fixed, backup, err
+		}
+		fixed++
+	}
+	if r.store == nil || len(r.changed) == 0 {
+		return fixed, "", nil
+	}
+
+	data, err := os.ReadFile(r.Path)
+	if err != nil {
+		return fixed, "", err
+	}
+	backup = fmt.Sprintf("%s.%s.bak", r.Path, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return fixed, "", err
+	}
+	if err := r.store.save(); err != nil {
+		return fixed, backup, err
+	}
+	for i := range r.original {
+		if !r.changed[i] {
+			continue
+		}
+		cred := r.store.Credentials[i]
+		r.store.auditChange(AuditUpdate, cred, ChangedFields(r.original[i], cred))
+	}
+	return fixed, backup, nil
+}
@@ -0,0 +1,119 @@
+package credential
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+	"golang.org/x/crypto/ssh"
+)
+
+const ageBinaryHeader = "age-encryption.org/v1"
+
+// ParseRecipient accepts an age recipient (age1...), an SSH public key, or the
+// path of a file holding either
+func ParseRecipient(value string) (age.Recipient, error) {
+	value = strings.TrimSpace(value)
+	if data, err := os.ReadFile(value); err == nil {
+		value = strings.TrimSpace(string(data))
+	}
+
+	if strings.HasPrefix(value, "age1") {
+		return age.ParseX25519Recipient(value)
+	}
+	if strings.HasPrefix(value, "ssh-") || strings.HasPrefix(value, "ecdsa-") {
+		recipient, err := agessh.ParseRecipient(value)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported recipient: %w (use an ed25519 or RSA key)", err)
+		}
+		return recipient, nil
+	}
+	return nil, fmt.Errorf("invalid recipient %q: expected an age recipient, an SSH public key, or a file containing one", value)
+}
+
+// LoadIdentity reads an age identity file or an SSH private key. passphrase
+// is asked for only when an encrypted SSH key is needed for decryption.
+func LoadIdentity(path string, passphrase func() ([]byte, error)) ([]age.Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(data, []byte("AGE-SECRET-KEY-")) {
+		return age.ParseIdentities(bytes.NewReader(data))
+	}
+
+	identity, err := agessh.ParseIdentity(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		pub := missing.PublicKey
+		if pub == nil {
+			if pub, _, err = LoadPublicKey(path); err != nil {
+				return nil, fmt.Errorf("%s is encrypted and its public key is needed: %w", path, err)
+			}
+		}
+		identity, err = agessh.NewEncryptedSSHIdentity(pub, data, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity %s: %w", path, err)
+	}
+	return []age.Identity{identity}, nil
+}
+
+// Encrypt encrypts data to the recipients as ASCII-armored age
+func Encrypt(data []byte, recipients ...age.Recipient) ([]byte, error) {
+	var out bytes.Buffer
+	armored := armor.NewWriter(&out)
+	w, err := age.Encrypt(armored, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := armored.Close(); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// IsEncrypted reports whether data is an age file, armored or not
+func IsEncrypted(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return bytes.HasPrefix(trimmed, []byte(armor.Header)) || bytes.HasPrefix(trimmed, []byte(ageBinaryHeader))
+}
+
+// IsPassphraseEncrypted reports whether an age file was encrypted with a
+// passphrase rather than to recipients
+func IsPassphraseEncrypted(data []byte) bool {
+	r := io.Reader(bytes.NewReader(data))
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		r = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	header := make([]byte, 256)
+	n, _ := io.ReadFull(r, header)
+	return bytes.Contains(header[:n], []byte("\n-> scrypt "))
+}
+
+// Decrypt decrypts an age file, armored or not
+func Decrypt(data []byte, identities ...age.Identity) ([]byte, error) {
+	r := io.Reader(bytes.NewReader(data))
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		r = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	plain, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return io.ReadAll(plain)
+}
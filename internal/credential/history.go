@@ -0,0 +1,90 @@
+package credential
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type SortOrder string
+
+const (
+	SortName     SortOrder = "name"
+	SortCreated  SortOrder = "created"
+	SortRecent   SortOrder = "recent"
+	SortFrequent SortOrder = "frequent"
+)
+
+// ParseSortOrder validates a sort order given on the command line
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(value); order {
+	case SortName, SortCreated, SortRecent, SortFrequent:
+		return order, nil
+	default:
+		return "", fmt.Errorf("invalid sort order %q: use recent, frequent, name, or created", value)
+	}
+}
+
+// SortCredentials returns a sorted copy of the credentials
+func SortCredentials(creds []SSHCredential, order SortOrder) []SSHCredential {
+	sorted := make([]SSHCredential, len(creds))
+	copy(sorted, creds)
+
+	var less func(a, b SSHCredential) bool
+	switch order {
+	case SortName:
+		less = func(a, b SSHCredential) bool { return a.Name < b.Name }
+	case SortRecent:
+		less = func(a, b SSHCredential) bool { return lastUsed(a).After(lastUsed(b)) }
+	case SortFrequent:
+		less = func(a, b SSHCredential) bool {
+			if a.UseCount != b.UseCount {
+				return a.UseCount > b.UseCount
+			}
+			return lastUsed(a).After(lastUsed(b))
+		}
+	default:
+		less = func(a, b SSHCredential) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted
+}
+
+func lastUsed(cred SSHCredential) time.Time {
+	if cred.LastUsedAt == nil {
+		return time.Time{}
+	}
+	return *cred.LastUsedAt
+}
+
+// RecordUse updates the last-used timestamp and use count of a credential
+func (s *CredentialStore) RecordUse(name string) error {
+	for i := range s.Credentials {
+		if s.Credentials[i].Name == name {
+			now := time.Now()
+			s.Credentials[i].LastUsedAt = &now
+			s.Credentials[i].UseCount++
+			return s.save()
+		}
+	}
+	return fmt.Errorf("credential not found: %s", name)
+}
+
+// LastUsedCredential returns the most recently used credential
+func (s *CredentialStore) LastUsedCredential() (*SSHCredential, error) {
+	var last *SSHCredential
+	for i, cred := range s.Credentials {
+		if cred.LastUsedAt == nil {
+			continue
+		}
+		if last == nil || cred.LastUsedAt.After(*last.LastUsedAt) {
+			last = &s.Credentials[i]
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("no credential has been used yet")
+	}
+	found := *last
+	return &found, nil
+}
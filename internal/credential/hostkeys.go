@@ -0,0 +1,73 @@
+package credential
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KnownHostsPath is the known_hosts file holding host keys pinned by ssh-cli
+func (s *CredentialStore) KnownHostsPath() string {
+	return filepath.Join(filepath.Dir(s.filepath), "known_hosts")
+}
+
+// PinHostKeys copies the host keys ssh recorded in sessionFile, a known_hosts
+// file used for a single session, into the store's known_hosts file and makes
+// the credential accept only those keys from then on. It returns the number
+// of keys found.
+func (s *CredentialStore) PinHostKeys(cred *SSHCredential, sessionFile string) (int, error) {
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return 0, err
+	}
+	path := s.KnownHostsPath()
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	known := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		known[strings.TrimSpace(line)] = true
+	}
+
+	var added bytes.Buffer
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		count++
+		if !known[line] {
+			known[line] = true
+			added.WriteString(line + "\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	if added.Len() > 0 {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := f.Write(added.Bytes()); err != nil {
+			f.Close()
+			return 0, err
+		}
+		if err := f.Close(); err != nil {
+			return 0, err
+		}
+	}
+
+	cred.SetSSHOption("UserKnownHostsFile", path)
+	cred.SetSSHOption("StrictHostKeyChecking", "yes")
+	return count, nil
+}
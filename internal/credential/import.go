@@ -0,0 +1,314 @@
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImportStrategy decides what happens to an imported credential whose name
+// or ID is already in the store
+type ImportStrategy string
+
+const (
+	// ImportSkip keeps the local credential and ignores the imported one
+	ImportSkip ImportStrategy = "skip"
+	// ImportOverwrite replaces the local credential with the same name
+	ImportOverwrite ImportStrategy = "overwrite"
+	// ImportRename imports under a new name when the name is taken
+	ImportRename ImportStrategy = "rename"
+	// ImportMerge updates the local credential with the same ID, filling in
+	// the fields the bundle sets, and imports the rest like ImportRename
+	ImportMerge ImportStrategy = "merge"
+	// ImportUpdateByName updates the local credential with the same name,
+	// filling in the fields the bundle sets
+	ImportUpdateByName ImportStrategy = "update"
+)
+
+// ParseImportStrategy validates a strategy name
+func ParseImportStrategy(value string) (ImportStrategy, error) {
+	switch strategy := ImportStrategy(strings.ToLower(strings.TrimSpace(value))); strategy {
+	case ImportSkip, ImportOverwrite, ImportRename, ImportMerge, ImportUpdateByName:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("invalid strategy %q: use skip, overwrite, rename, merge or update", value)
+}
+
+// ImportAction is what an import does with one entry
+type ImportAction string
+
+const (
+	ImportAdd     ImportAction = "add"
+	ImportUpdate  ImportAction = "update"
+	ImportSkipped ImportAction = "skip"
+	ImportInvalid ImportAction = "invalid"
+)
+
+// ImportItem is the planned outcome for one bundle entry
+type ImportItem struct {
+	// Name is the entry's name in the bundle; Credential.Name may differ when renamed
+	Name       string
+	Action     ImportAction
+	Credential SSHCredential
+	// Replaces names the local credential that is updated
+	Replaces string
+	Fields   []string
+	Reason   string
+	Err      error
+
+	keyData  string
+	writeKey bool
+}
+
+func (i ImportItem) String() string {
+	switch i.Action {
+	case ImportAdd:
+		if i.Credential.Name != i.Name {
+			return fmt.Sprintf("+ %s (renamed from %s)", i.Credential.Name, i.Name)
+		}
+		return "+ " + i.Name
+	case ImportUpdate:
+		return fmt.Sprintf("~ %s: %s", i.Replaces, strings.Join(i.Fields, ", "))
+	case ImportSkipped:
+		return fmt.Sprintf("= %s: %s", i.Name, i.Reason)
+	}
+	return fmt.Sprintf("! %s: %v", i.Name, i.Err)
+}
+
+// PlanImport works out what importing entries would do without changing
+// anything. Every entry is validated on its own; invalid ones are reported and
+// left out rather than failing the whole import.
+func (s *CredentialStore) PlanImport(entries []BundleEntry, strategy ImportStrategy) []ImportItem {
+	names := make(map[string]bool)
+	ids := make(map[string]bool)
+	byName := make(map[string]SSHCredential)
+	byID := make(map[string]SSHCredential)
+	for _, cred := range s.Credentials {
+		names[cred.Name] = true
+		ids[cred.ID] = true
+		byName[cred.Name] = cred
+		byID[cred.ID] = cred
+	}
+	planned := make(map[string]bool)
+
+	uniqueName := func(name string) string {
+		for n := 2; ; n++ {
+			candidate := fmt.Sprintf("%s-%d", name, n)
+			if !names[candidate] {
+				return candidate
+			}
+		}
+	}
+
+	items := make([]ImportItem, 0, len(entries))
+	for _, entry := range entries {
+		cred := entry.SSHCredential
+		cred.Name = strings.ToLower(strings.TrimSpace(cred.Name))
+		cred.DeletedAt = nil
+		item := ImportItem{Name: cred.Name, Action: ImportAdd, keyData: entry.KeyData}
+
+		existing, sameID := byID[cred.ID]
+		if cred.ID != "" && planned[cred.ID] {
+			item.Action, item.Err = ImportInvalid, errors.New("duplicate ID in bundle")
+			items = append(items, item)
+			continue
+		}
+
+		switch {
+		case strategy == ImportMerge && cred.ID != "" && sameID:
+			merged := mergeImported(existing, cred)
+			if merged.Name != existing.Name && names[merged.Name] {
+				merged.Name = uniqueName(merged.Name)
+			}
+			item.Action, item.Replaces, cred = ImportUpdate, existing.Name, merged
+		case names[cred.Name]:
+			switch strategy {
+			case ImportSkip:
+				item.Action, item.Reason = ImportSkipped, "already exists"
+			case ImportUpdateByName:
+				local, ok := byName[cred.Name]
+				if !ok {
+					item.Action, item.Err = ImportInvalid, errors.New("duplicate name in bundle")
+					break
+				}
+				item.Action, item.Replaces, cred = ImportUpdate, local.Name, mergeImported(local, cred)
+			case ImportOverwrite:
+				local, ok := byName[cred.Name]
+				if !ok {
+					item.Action, item.Err = ImportInvalid, errors.New("duplicate name in bundle")
+					break
+				}
+				cred.ID = local.ID
+				cred.CreatedAt = local.CreatedAt
+				cred.LastUsedAt, cred.UseCount = local.LastUsedAt, local.UseCount
+				item.Action, item.Replaces = ImportUpdate, local.Name
+			default:
+				cred.Name = uniqueName(cred.Name)
+			}
+		}
+
+		if item.Action == ImportAdd {
+			if cred.ID == "" || ids[cred.ID] {
+				id, err := GenerateID()
+				if err != nil {
+					item.Action, item.Err = ImportInvalid, err
+				} else {
+					cred.ID = id
+				}
+			}
+			if cred.CreatedAt.IsZero() {
+				cred.CreatedAt = time.Now()
+			}
+			if cred.UpdatedAt.IsZero() {
+				cred.UpdatedAt = cred.CreatedAt
+			}
+		}
+
+		if item.Action == ImportAdd || item.Action == ImportUpdate {
+			if item.keyData != "" && cred.AuthType == KeyFile && cred.KeyRef == "" {
+				cred.KeyPath, item.writeKey = s.importKeyPath(cred, item.keyData)
+			}
+			if err := validateImported(cred, item); err != nil {
+				item.Action, item.Err = ImportInvalid, err
+			}
+		}
+
+		if item.Action == ImportUpdate {
+			old := byName[item.Replaces]
+			item.Fields = ChangedFields(old, cred)
+			if len(item.Fields) == 0 && !item.writeKey {
+				item.Action, item.Reason = ImportSkipped, "unchanged"
+			} else {
+				cred.UpdatedAt = time.Now()
+			}
+		}
+
+		item.Credential = cred
+		if item.Action == ImportAdd || item.Action == ImportUpdate {
+			delete(names, item.Replaces)
+			names[cred.Name] = true
+			ids[cred.ID] = true
+			planned[cred.ID] = true
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// ApplyImport carries out a plan made by PlanImport, saving the store once and
+// then writing embedded keys, so a failed save leaves no keys behind
+func (s *CredentialStore) ApplyImport(items []ImportItem) error {
+	var audits []func()
+	var keys []ImportItem
+	for _, item := range items {
+		cred := item.Credential
+		if item.Action != ImportAdd && item.Action != ImportUpdate {
+			continue
+		}
+		if item.writeKey {
+			keys = append(keys, item)
+		}
+
+		if item.Action == ImportAdd {
+			s.Credentials = append(s.Credentials, cred)
+			audits = append(audits, func() { s.auditChange(AuditSave, cred, nil) })
+			continue
+		}
+		for i, existing := range s.Credentials {
+			if existing.Name == item.Replaces {
+				s.Credentials[i] = cred
+				fields := item.Fields
+				audits = append(audits, func() { s.auditChange(AuditUpdate, cred, fields) })
+				break
+			}
+		}
+	}
+
+	if err := s.save(); err != nil {
+		return err
+	}
+	for _, audit := range audits {
+		audit()
+	}
+
+	// A key that cannot be written is reported; importing again writes it
+	// once the problem is fixed, since the file does not hold the key yet
+	var errs []error
+	for _, item := range keys {
+		path := item.Credential.KeyPath
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to write key: %w", item.Credential.Name, err))
+			continue
+		}
+		if err := os.WriteFile(path, []byte(item.keyData), 0600); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to write key: %w", item.Credential.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// importKeyPath decides where an embedded key goes: its original path only
+// when that already holds the same key, otherwise a file next to the store. A
+// bundle never chooses where new files are written.
+func (s *CredentialStore) importKeyPath(cred SSHCredential, keyData string) (string, bool) {
+	if cred.KeyPath != "" {
+		if data, err := os.ReadFile(cred.KeyPath); err == nil && string(data) == keyData {
+			return cred.KeyPath, false
+		}
+	}
+	return filepath.Join(filepath.Dir(s.filepath), "keys", cred.ID), true
+}
+
+// validateImported validates a credential whose embedded key has not been
+// written yet. The key comes with the bundle, so only the rest is checked.
+func validateImported(cred SSHCredential, item ImportItem) error {
+	if item.writeKey {
+		cred.KeyPath = ""
+	}
+	return cred.Validate()
+}
+
+// mergeImported fills in a local credential with every field the imported one
+// sets. Tags and ssh options are combined; usage history stays local.
+func mergeImported(local, imported SSHCredential) SSHCredential {
+	merged := local
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	set(&merged.Name, imported.Name)
+	set(&merged.Host, imported.Host)
+	set(&merged.Username, imported.Username)
+	set((*string)(&merged.AuthType), string(imported.AuthType))
+	set(&merged.Password, imported.Password)
+	set(&merged.PasswordRef, imported.PasswordRef)
+	set(&merged.KeyPath, imported.KeyPath)
+	set(&merged.KeyRef, imported.KeyRef)
+	set(&merged.KeyPassRef, imported.KeyPassRef)
+	set(&merged.CertPath, imported.CertPath)
+	set(&merged.RemoteCommand, imported.RemoteCommand)
+	if imported.Port != 0 {
+		merged.Port = imported.Port
+	}
+	if len(imported.ExtraArgs) > 0 {
+		merged.ExtraArgs = imported.ExtraArgs
+	}
+	if len(imported.Tags) > 0 {
+		tags := make(map[string]string, len(local.Tags)+len(imported.Tags))
+		for k, v := range local.Tags {
+			tags[k] = v
+		}
+		for k, v := range imported.Tags {
+			tags[k] = v
+		}
+		merged.Tags = tags
+	}
+	if len(imported.SSHOptions) > 0 {
+		merged.SSHOptions = MergeSSHOptions(local.SSHOptions, imported.SSHOptions)
+	}
+	return merged
+}
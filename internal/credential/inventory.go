@@ -0,0 +1,261 @@
+package credential
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Inventory formats
+const (
+	InventoryYAML = "yaml"
+	InventoryCSV  = "csv"
+)
+
+// InventoryHost is one host in an inventory file used for bulk saves
+type InventoryHost struct {
+	Name          string            `yaml:"name"`
+	Host          string            `yaml:"host"`
+	Port          int               `yaml:"port"`
+	User          string            `yaml:"user"`
+	Auth          string            `yaml:"auth"`
+	Key           string            `yaml:"key"`
+	KeyRef        string            `yaml:"key_ref"`
+	PasswordRef   string            `yaml:"password_ref"`
+	Jump          string            `yaml:"jump"`
+	Tags          InventoryTags     `yaml:"tags"`
+	Options       map[string]string `yaml:"options"`
+	RemoteCommand string            `yaml:"remote_command"`
+}
+
+// InventoryTags accepts tags as a map, a list of key=value strings, or a
+// single comma separated string
+type InventoryTags map[string]string
+
+func (t *InventoryTags) UnmarshalYAML(node *yaml.Node) error {
+	var tags map[string]string
+	switch node.Kind {
+	case yaml.MappingNode:
+		if err := node.Decode(&tags); err != nil {
+			return err
+		}
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		var err error
+		if tags, err = parseTagList(list); err != nil {
+			return err
+		}
+	case yaml.ScalarNode:
+		var err error
+		if tags, err = parseTagList(strings.Split(node.Value, ",")); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("line %d: tags must be a map, a list or a string", node.Line)
+	}
+	*t = tags
+	return nil
+}
+
+func parseTagList(list []string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, tag := range list {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		key, value, err := ParseTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		tags[key] = strings.TrimSpace(value)
+	}
+	return tags, nil
+}
+
+// ParseInventory reads hosts from a YAML or CSV inventory. YAML is either a
+// list of hosts or a map with hosts and defaults applied to every host.
+// CSV has a header row naming the same fields; tags are k=v pairs separated
+// by semicolons and options are Key=Value pairs separated the same way.
+func ParseInventory(data []byte, format string) ([]InventoryHost, error) {
+	switch format {
+	case InventoryYAML:
+		return parseYAMLInventory(data)
+	case InventoryCSV:
+		return parseCSVInventory(data)
+	}
+	return nil, fmt.Errorf("invalid inventory format %q: use yaml or csv", format)
+}
+
+// inventoryFile is the map form of a YAML inventory
+type inventoryFile struct {
+	Defaults InventoryHost   `yaml:"defaults"`
+	Hosts    []InventoryHost `yaml:"hosts"`
+}
+
+func parseYAMLInventory(data []byte) ([]InventoryHost, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid inventory: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.New("inventory is empty")
+	}
+
+	var inventory inventoryFile
+	// Like unknown CSV columns, unknown keys are rejected rather than
+	// silently dropping a misspelled field from every host
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var err error
+	if doc.Content[0].Kind == yaml.SequenceNode {
+		err = decoder.Decode(&inventory.Hosts)
+	} else {
+		err = decoder.Decode(&inventory)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid inventory: %w", err)
+	}
+
+	for i := range inventory.Hosts {
+		inventory.Hosts[i].applyDefaults(inventory.Defaults)
+	}
+	return inventory.Hosts, nil
+}
+
+func parseCSVInventory(data []byte) ([]InventoryHost, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid inventory: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil, errors.New("inventory has no hosts")
+	}
+
+	header := rows[0]
+	hosts := make([]InventoryHost, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		var h InventoryHost
+		for i, column := range header {
+			value := strings.TrimSpace(row[i])
+			if value == "" {
+				continue
+			}
+			switch strings.ToLower(strings.TrimSpace(column)) {
+			case "name":
+				h.Name = value
+			case "host":
+				h.Host = value
+			case "port":
+				if h.Port, err = strconv.Atoi(value); err != nil {
+					return nil, fmt.Errorf("line %d: invalid port %q", n+2, value)
+				}
+			case "user":
+				h.User = value
+			case "auth":
+				h.Auth = value
+			case "key":
+				h.Key = value
+			case "key_ref":
+				h.KeyRef = value
+			case "password_ref":
+				h.PasswordRef = value
+			case "jump":
+				h.Jump = value
+			case "remote_command":
+				h.RemoteCommand = value
+			case "tags":
+				if h.Tags, err = parseTagList(strings.Split(value, ";")); err != nil {
+					return nil, fmt.Errorf("line %d: %w", n+2, err)
+				}
+			case "options":
+				h.Options = make(map[string]string)
+				for _, option := range strings.Split(value, ";") {
+					key, val, err := ParseSSHOption(option)
+					if err != nil {
+						return nil, fmt.Errorf("line %d: %w", n+2, err)
+					}
+					h.Options[key] = val
+				}
+			default:
+				return nil, fmt.Errorf("unknown inventory column %q", column)
+			}
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+// applyDefaults fills unset fields from defaults; tags and options are combined
+func (h *InventoryHost) applyDefaults(d InventoryHost) {
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fill(&h.User, d.User)
+	fill(&h.Auth, d.Auth)
+	fill(&h.Key, d.Key)
+	fill(&h.KeyRef, d.KeyRef)
+	fill(&h.PasswordRef, d.PasswordRef)
+	fill(&h.Jump, d.Jump)
+	fill(&h.RemoteCommand, d.RemoteCommand)
+	if h.Port == 0 {
+		h.Port = d.Port
+	}
+	if len(d.Tags) > 0 {
+		tags := make(InventoryTags, len(d.Tags)+len(h.Tags))
+		for k, v := range d.Tags {
+			tags[k] = v
+		}
+		for k, v := range h.Tags {
+			tags[k] = v
+		}
+		h.Tags = tags
+	}
+	if len(d.Options) > 0 {
+		h.Options = MergeSSHOptions(d.Options, h.Options)
+	}
+}
+
+// Credential converts the host to a credential. Unset fields stay empty so
+// that updating an existing credential only changes what the inventory sets.
+// A jump host becomes the ProxyJump ssh option.
+func (h InventoryHost) Credential() SSHCredential {
+	cred := SSHCredential{
+		Name:          strings.TrimSpace(h.Name),
+		Host:          strings.TrimSpace(h.Host),
+		Port:          h.Port,
+		Username:      strings.TrimSpace(h.User),
+		AuthType:      AuthType(strings.TrimSpace(h.Auth)),
+		KeyPath:       strings.TrimSpace(h.Key),
+		KeyRef:        strings.TrimSpace(h.KeyRef),
+		PasswordRef:   strings.TrimSpace(h.PasswordRef),
+		RemoteCommand: strings.TrimSpace(h.RemoteCommand),
+	}
+	if cred.Name == "" {
+		cred.Name = cred.Host
+	}
+	if cred.AuthType == "" && cred.PasswordRef != "" {
+		cred.AuthType = Password
+	}
+	if len(h.Tags) > 0 {
+		cred.Tags = map[string]string(h.Tags)
+	}
+	if len(h.Options) > 0 {
+		cred.SSHOptions = h.Options
+	}
+	if jump := strings.TrimSpace(h.Jump); jump != "" {
+		cred.SetSSHOption("ProxyJump", jump)
+	}
+	return cred
+}
@@ -0,0 +1,115 @@
+package credential
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyInfo describes a private key file
+type KeyInfo struct {
+	Type        string
+	Bits        int
+	Fingerprint string
+	Encrypted   bool
+}
+
+func (k *KeyInfo) String() string {
+	s := fmt.Sprintf("%s %d %s", k.Type, k.Bits, k.Fingerprint)
+	if k.Encrypted {
+		s += " (encrypted)"
+	}
+	return s
+}
+
+// ErrWrongPassphrase is returned when a passphrase does not decrypt a key
+var ErrWrongPassphrase = errors.New("incorrect passphrase for private key")
+
+// InspectKey parses a private key and reports its type, size and fingerprint. An
+// encrypted key is only decrypted when a passphrase is given; otherwise its public
+// half is read from the key itself or from the .pub file next to it.
+func InspectKey(path string, passphrase []byte) (*KeyInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Permissions are checked by the commands, which decide whether open ones
+	// are an error (see CheckPermissions)
+	info := &KeyInfo{}
+
+	var pub ssh.PublicKey
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case err == nil:
+		pub = signer.PublicKey()
+	case errors.As(err, &missing):
+		info.Encrypted = true
+		pub = missing.PublicKey
+		if len(passphrase) > 0 {
+			signer, err := ssh.ParsePrivateKeyWithPassphrase(data, passphrase)
+			if err != nil {
+				if errors.Is(err, x509.IncorrectPasswordError) {
+					return nil, ErrWrongPassphrase
+				}
+				return nil, fmt.Errorf("failed to decrypt private key %s: %w", path, err)
+			}
+			pub = signer.PublicKey()
+		}
+		if pub == nil {
+			pub, _, _ = LoadPublicKey(path)
+		}
+	default:
+		return nil, fmt.Errorf("invalid private key %s: %w", path, err)
+	}
+
+	if pub == nil {
+		info.Type = "unknown"
+		return info, nil
+	}
+
+	info.Type = keyTypeName(pub.Type())
+	info.Bits = keyBits(pub)
+	info.Fingerprint = ssh.FingerprintSHA256(pub)
+	return info, nil
+}
+
+func keyTypeName(algo string) string {
+	switch {
+	case strings.HasPrefix(algo, "ssh-ed25519"):
+		return "ED25519"
+	case strings.HasPrefix(algo, "ssh-rsa"):
+		return "RSA"
+	case strings.HasPrefix(algo, "ecdsa-"):
+		return "ECDSA"
+	case strings.HasPrefix(algo, "ssh-dss"):
+		return "DSA"
+	case strings.HasPrefix(algo, "sk-"):
+		return strings.ToUpper(strings.TrimPrefix(algo, "sk-"))
+	}
+	return algo
+}
+
+func keyBits(pub ssh.PublicKey) int {
+	cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return 0
+	}
+	switch k := cryptoPub.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize
+	}
+	if pub.Type() == ssh.KeyAlgoED25519 {
+		return 256
+	}
+	return 0
+}
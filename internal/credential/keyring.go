@@ -0,0 +1,245 @@
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// KeyringScheme prefixes password references that live in the system keyring
+const KeyringScheme = "keyring://"
+
+// ErrSecretNotFound is returned when a keyring has no secret for a key
+var ErrSecretNotFound = errors.New("secret not found in keyring")
+
+// Keyring stores secrets outside of credentials.json
+type Keyring interface {
+	Get(key string) (string, error)
+	Set(key, label, secret string) error
+	Delete(key string) error
+}
+
+const (
+	secretServiceName      = "org.freedesktop.secrets"
+	secretServicePath      = "/org/freedesktop/secrets"
+	secretServiceInterface = "org.freedesktop.Secret.Service"
+	secretCollectionIface  = "org.freedesktop.Secret.Collection"
+	secretItemInterface    = "org.freedesktop.Secret.Item"
+	secretPromptInterface  = "org.freedesktop.Secret.Prompt"
+	defaultCollectionAlias = "/org/freedesktop/secrets/aliases/default"
+	keyringServiceAttr     = "ssh-cli"
+)
+
+// secret mirrors the Secret struct of the Secret Service API
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// SecretService is a Keyring backed by the freedesktop Secret Service over D-Bus,
+// as provided by GNOME Keyring and KWallet
+type SecretService struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// NewSecretService connects to the Secret Service on the session bus
+func NewSecretService() (*SecretService, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %w", err)
+	}
+	return NewSecretServiceWithConn(conn)
+}
+
+// NewSecretServiceWithConn uses an existing D-Bus connection, such as a private test bus
+func NewSecretServiceWithConn(conn *dbus.Conn) (*SecretService, error) {
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err := conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secret service session: %w", err)
+	}
+	return &SecretService{conn: conn, session: session}, nil
+}
+
+func (s *SecretService) attributes(key string) map[string]string {
+	return map[string]string{"service": keyringServiceAttr, "credential": key}
+}
+
+func (s *SecretService) service() dbus.BusObject {
+	return s.conn.Object(secretServiceName, secretServicePath)
+}
+
+// collection returns the default collection, unlocking it if needed
+func (s *SecretService) collection() (dbus.BusObject, error) {
+	var path dbus.ObjectPath
+	if err := s.service().Call(secretServiceInterface+".ReadAlias", 0, "default").Store(&path); err != nil {
+		return nil, fmt.Errorf("failed to find the default keyring: %w", err)
+	}
+	if path == "/" {
+		return nil, errors.New("no default keyring collection is configured")
+	}
+	if err := s.unlock(path); err != nil {
+		return nil, err
+	}
+	return s.conn.Object(secretServiceName, path), nil
+}
+
+func (s *SecretService) unlock(paths ...dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := s.service().Call(secretServiceInterface+".Unlock", 0, paths).Store(&unlocked, &prompt); err != nil {
+		return fmt.Errorf("failed to unlock keyring: %w", err)
+	}
+	return s.prompt(prompt)
+}
+
+// prompt runs a Secret Service prompt, such as the desktop's unlock dialog, and
+// waits for it to finish
+func (s *SecretService) prompt(path dbus.ObjectPath) error {
+	if path == "/" || path == "" {
+		return nil
+	}
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(secretPromptInterface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal(match...)
+
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.conn.Object(secretServiceName, path).Call(secretPromptInterface+".Prompt", 0, "").Err; err != nil {
+		return fmt.Errorf("failed to show keyring prompt: %w", err)
+	}
+
+	for signal := range signals {
+		if signal.Path != path || signal.Name != secretPromptInterface+".Completed" {
+			continue
+		}
+		if len(signal.Body) > 0 && signal.Body[0] == true {
+			return errors.New("keyring prompt was dismissed")
+		}
+		return nil
+	}
+	return errors.New("keyring connection closed while waiting for prompt")
+}
+
+func (s *SecretService) find(key string) (dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	if err := s.service().Call(secretServiceInterface+".SearchItems", 0, s.attributes(key)).Store(&unlocked, &locked); err != nil {
+		return "", fmt.Errorf("failed to search keyring: %w", err)
+	}
+	if len(unlocked) > 0 {
+		return unlocked[0], nil
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked[0]); err != nil {
+			return "", err
+		}
+		return locked[0], nil
+	}
+	return "", ErrSecretNotFound
+}
+
+func (s *SecretService) Get(key string) (string, error) {
+	item, err := s.find(key)
+	if err != nil {
+		return "", err
+	}
+
+	var sec secret
+	if err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".GetSecret", 0, s.session).Store(&sec); err != nil {
+		return "", fmt.Errorf("failed to read secret from keyring: %w", err)
+	}
+	return string(sec.Value), nil
+}
+
+func (s *SecretService) Set(key, label, value string) error {
+	collection, err := s.collection()
+	if err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		secretItemInterface + ".Label":      dbus.MakeVariant(label),
+		secretItemInterface + ".Attributes": dbus.MakeVariant(s.attributes(key)),
+	}
+	sec := secret{Session: s.session, Value: []byte(value), ContentType: "text/plain; charset=utf8"}
+
+	var item, prompt dbus.ObjectPath
+	if err := collection.Call(secretCollectionIface+".CreateItem", 0, properties, sec, true).Store(&item, &prompt); err != nil {
+		return fmt.Errorf("failed to store secret in keyring: %w", err)
+	}
+	return s.prompt(prompt)
+}
+
+func (s *SecretService) Delete(key string) error {
+	item, err := s.find(key)
+	if err != nil {
+		return err
+	}
+
+	var prompt dbus.ObjectPath
+	if err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".Delete", 0).Store(&prompt); err != nil {
+		return fmt.Errorf("failed to delete secret from keyring: %w", err)
+	}
+	return s.prompt(prompt)
+}
+
+// OpenKeyring opens the system keyring. It is a variable so a fake keyring can be swapped in.
+var OpenKeyring = func() (Keyring, error) {
+	return NewSecretService()
+}
+
+// KeyringRef returns the password reference for a credential stored in the keyring
+func KeyringRef(cred SSHCredential) string {
+	return KeyringScheme + cred.ID
+}
+
+// MovePasswordToKeyring stores the credential's password in the keyring and
+// replaces it with a reference
+func (c *SSHCredential) MovePasswordToKeyring(kr Keyring) error {
+	if c.Password == "" {
+		return errors.New("no password to store in keyring")
+	}
+	if err := kr.Set(c.ID, fmt.Sprintf("ssh-cli: %s@%s (%s)", c.Username, c.Host, c.Name), c.Password); err != nil {
+		return err
+	}
+	c.PasswordRef = KeyringRef(*c)
+	c.Password = ""
+	return nil
+}
+
+// forgetSecrets removes the keyring entries of credentials that no longer need
+// them, such as permanently deleted ones. It is best effort: a missing or
+// locked keyring must not block purging or updating the store.
+func forgetSecrets(creds []SSHCredential) {
+	var kr Keyring
+	for _, cred := range creds {
+		key, ok := strings.CutPrefix(cred.PasswordRef, KeyringScheme)
+		if !ok {
+			continue
+		}
+		if kr == nil {
+			var err error
+			if kr, err = OpenKeyring(); err != nil {
+				return
+			}
+		}
+		kr.Delete(key)
+	}
+}
@@ -0,0 +1,442 @@
+package credential
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// memoryKeyring is a Keyring kept in a map
+type memoryKeyring struct {
+	secrets map[string]string
+	deleted []string
+}
+
+func newMemoryKeyring() *memoryKeyring {
+	return &memoryKeyring{secrets: make(map[string]string)}
+}
+
+func (k *memoryKeyring) Get(key string) (string, error) {
+	value, ok := k.secrets[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (k *memoryKeyring) Set(key, label, secret string) error {
+	k.secrets[key] = secret
+	return nil
+}
+
+func (k *memoryKeyring) Delete(key string) error {
+	if _, ok := k.secrets[key]; !ok {
+		return ErrSecretNotFound
+	}
+	delete(k.secrets, key)
+	k.deleted = append(k.deleted, key)
+	return nil
+}
+
+// useKeyring swaps the keyring OpenKeyring returns for the rest of the test
+func useKeyring(t *testing.T, kr Keyring) {
+	t.Helper()
+	previous := OpenKeyring
+	OpenKeyring = func() (Keyring, error) { return kr, nil }
+	t.Cleanup(func() { OpenKeyring = previous })
+}
+
+func TestKeyringReferences(t *testing.T) {
+	kr := newMemoryKeyring()
+	useKeyring(t, kr)
+
+	cred := SSHCredential{ID: "abc123", Name: "db", Host: "db.internal", Username: "admin", AuthType: Password, Password: "hunter2"}
+	if err := cred.MovePasswordToKeyring(kr); err != nil {
+		t.Fatalf("MovePasswordToKeyring: %v", err)
+	}
+	if cred.Password != "" || cred.PasswordRef != "keyring://abc123" {
+		t.Fatalf("credential after move = %q, %q; want no password and keyring://abc123", cred.Password, cred.PasswordRef)
+	}
+
+	got, err := cred.ResolvePassword()
+	if err != nil || got != "hunter2" {
+		t.Fatalf("ResolvePassword = %q, %v; want hunter2", got, err)
+	}
+
+	if _, err := ResolveSecret("keyring://missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("ResolveSecret of a missing item = %v, want ErrSecretNotFound", err)
+	}
+
+	if err := (&SSHCredential{ID: "x"}).MovePasswordToKeyring(kr); err == nil {
+		t.Error("MovePasswordToKeyring without a password succeeded")
+	}
+}
+
+func TestForgetSecrets(t *testing.T) {
+	kr := newMemoryKeyring()
+	kr.secrets["a"] = "one"
+	kr.secrets["b"] = "two"
+	useKeyring(t, kr)
+
+	forgetSecrets([]SSHCredential{
+		{Name: "a", PasswordRef: "keyring://a"},
+		{Name: "plain", Password: "three"},
+		{Name: "gone", PasswordRef: "keyring://gone"},
+	})
+	if strings.Join(kr.deleted, ",") != "a" {
+		t.Errorf("deleted = %v, want [a]", kr.deleted)
+	}
+	if _, ok := kr.secrets["b"]; !ok {
+		t.Error("forgetSecrets removed a secret of a credential that was not purged")
+	}
+}
+
+func TestSwitchAuthTypeForgetsKeyringPassword(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	kr := newMemoryKeyring()
+	useKeyring(t, kr)
+
+	store, err := NewCredentialStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred := SSHCredential{ID: "id1", Name: "db", Host: "10.0.0.2", Port: 22, Username: "root", AuthType: Password, Password: "secret"}
+	if err := cred.MovePasswordToKeyring(kr); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveCredential(cred); err != nil {
+		t.Fatal(err)
+	}
+
+	cred.SetAuthType(KeyFile)
+	if cred.Password != "" || cred.PasswordRef != "" {
+		t.Errorf("password %q, reference %q left after switching to key", cred.Password, cred.PasswordRef)
+	}
+	if err := store.UpdateCredential("db", cred); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := kr.secrets["id1"]; ok {
+		t.Error("keyring still holds the password of a credential that switched to key")
+	}
+
+	cred.KeyPath, cred.CertPath = "/keys/db", "/keys/db-cert.pub"
+	cred.SetAuthType(Password)
+	if cred.KeyPath != "" || cred.CertPath != "" {
+		t.Errorf("key %q, certificate %q left after switching to password", cred.KeyPath, cred.CertPath)
+	}
+}
+
+// The tests below run SecretService against a fake Secret Service on a
+// private D-Bus daemon
+
+const fakeCollectionPath = dbus.ObjectPath("/org/freedesktop/secrets/collection/login")
+
+type fakeItem struct {
+	path       dbus.ObjectPath
+	attributes map[string]string
+	value      []byte
+}
+
+// fakeSecretService implements the parts of org.freedesktop.Secret.Service,
+// Collection, Item and Prompt that SecretService uses. The collection can be
+// locked; unlocking it goes through a prompt.
+type fakeSecretService struct {
+	conn *dbus.Conn
+
+	mu      sync.Mutex
+	locked  bool
+	items   []*fakeItem
+	next    int
+	prompts int
+}
+
+// state returns copies of the items and the number of prompts shown so far
+func (s *fakeSecretService) state() ([]fakeItem, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]fakeItem, len(s.items))
+	for i, item := range s.items {
+		items[i] = *item
+	}
+	return items, s.prompts
+}
+
+func (s *fakeSecretService) lock() {
+	s.mu.Lock()
+	s.locked = true
+	s.mu.Unlock()
+}
+
+type fakeService struct{ s *fakeSecretService }
+type fakeCollection struct{ s *fakeSecretService }
+type fakeItemObject struct {
+	s    *fakeSecretService
+	item *fakeItem
+}
+type fakePrompt struct {
+	s    *fakeSecretService
+	path dbus.ObjectPath
+}
+
+func (f fakeService) OpenSession(algorithm string, input dbus.Variant) (dbus.Variant, dbus.ObjectPath, *dbus.Error) {
+	if algorithm != "plain" {
+		return dbus.Variant{}, "", dbus.MakeFailedError(fmt.Errorf("unsupported algorithm %s", algorithm))
+	}
+	return dbus.MakeVariant(""), "/org/freedesktop/secrets/session/1", nil
+}
+
+func (f fakeService) ReadAlias(name string) (dbus.ObjectPath, *dbus.Error) {
+	if name != "default" {
+		return "/", nil
+	}
+	return fakeCollectionPath, nil
+}
+
+func (f fakeService) SearchItems(attributes map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath, *dbus.Error) {
+	s := f.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var unlocked, locked []dbus.ObjectPath
+	for _, item := range s.items {
+		match := true
+		for k, v := range attributes {
+			if item.attributes[k] != v {
+				match = false
+			}
+		}
+		switch {
+		case !match:
+		case s.locked:
+			locked = append(locked, item.path)
+		default:
+			unlocked = append(unlocked, item.path)
+		}
+	}
+	return unlocked, locked, nil
+}
+
+func (f fakeService) Unlock(objects []dbus.ObjectPath) ([]dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	s := f.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.locked {
+		return objects, "/", nil
+	}
+	s.prompts++
+	path := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/secrets/prompt/%d", s.prompts))
+	if err := s.conn.Export(fakePrompt{s, path}, path, secretPromptInterface); err != nil {
+		return nil, "", dbus.MakeFailedError(err)
+	}
+	return nil, path, nil
+}
+
+// Prompt stands in for the desktop's unlock dialog, which the user accepts
+func (p fakePrompt) Prompt(windowID string) *dbus.Error {
+	p.s.mu.Lock()
+	p.s.locked = false
+	p.s.mu.Unlock()
+	go p.s.conn.Emit(p.path, secretPromptInterface+".Completed", false, dbus.MakeVariant(""))
+	return nil
+}
+
+func (c fakeCollection) CreateItem(properties map[string]dbus.Variant, sec secret, replace bool) (dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locked {
+		return "", "", dbus.NewError("org.freedesktop.Secret.Error.IsLocked", nil)
+	}
+	attributes, _ := properties[secretItemInterface+".Attributes"].Value().(map[string]string)
+	for _, item := range s.items {
+		if replace && fmt.Sprint(item.attributes) == fmt.Sprint(attributes) {
+			item.value = sec.Value
+			return item.path, "/", nil
+		}
+	}
+	s.next++
+	item := &fakeItem{
+		path:       dbus.ObjectPath(fmt.Sprintf("%s/%d", fakeCollectionPath, s.next)),
+		attributes: attributes,
+		value:      sec.Value,
+	}
+	if err := s.conn.Export(fakeItemObject{s, item}, item.path, secretItemInterface); err != nil {
+		return "", "", dbus.MakeFailedError(err)
+	}
+	s.items = append(s.items, item)
+	return item.path, "/", nil
+}
+
+func (i fakeItemObject) GetSecret(session dbus.ObjectPath) (secret, *dbus.Error) {
+	i.s.mu.Lock()
+	defer i.s.mu.Unlock()
+	if i.s.locked {
+		return secret{}, dbus.NewError("org.freedesktop.Secret.Error.IsLocked", nil)
+	}
+	return secret{Session: session, Value: i.item.value, ContentType: "text/plain"}, nil
+}
+
+func (i fakeItemObject) Delete() (dbus.ObjectPath, *dbus.Error) {
+	s := i.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for n, item := range s.items {
+		if item == i.item {
+			s.items = append(s.items[:n], s.items[n+1:]...)
+			s.conn.Export(nil, item.path, secretItemInterface)
+			return "/", nil
+		}
+	}
+	return "", dbus.NewError("org.freedesktop.Secret.Error.NoSuchObject", nil)
+}
+
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startBus runs a private D-Bus daemon for the test and returns its address
+func startBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "bus")
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(fmt.Sprintf(busConfig, socket)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--nopidfile", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	// The address is printed once the daemon accepts connections
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("dbus-daemon did not start: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// startSecretService exports a fake Secret Service on a private bus and
+// returns a SecretService client connected to it
+func startSecretService(t *testing.T, locked bool) (*SecretService, *fakeSecretService) {
+	t.Helper()
+	address := startBus(t)
+
+	serverConn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("failed to connect to the test bus: %v", err)
+	}
+	t.Cleanup(func() { serverConn.Close() })
+	fake := &fakeSecretService{conn: serverConn, locked: locked}
+	if err := serverConn.Export(fakeService{fake}, secretServicePath, secretServiceInterface); err != nil {
+		t.Fatal(err)
+	}
+	if err := serverConn.Export(fakeCollection{fake}, fakeCollectionPath, secretCollectionIface); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := serverConn.RequestName(secretServiceName, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own %s: %v", secretServiceName, err)
+	}
+
+	clientConn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("failed to connect to the test bus: %v", err)
+	}
+	t.Cleanup(func() { clientConn.Close() })
+	ss, err := NewSecretServiceWithConn(clientConn)
+	if err != nil {
+		t.Fatalf("NewSecretServiceWithConn: %v", err)
+	}
+	return ss, fake
+}
+
+func TestSecretService(t *testing.T) {
+	ss, fake := startSecretService(t, false)
+
+	if err := ss.Set("abc123", "ssh-cli: admin@db", "hunter2"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, err := ss.Get("abc123"); err != nil || got != "hunter2" {
+		t.Fatalf("Get = %q, %v; want hunter2", got, err)
+	}
+	items, _ := fake.state()
+	if want := map[string]string{"service": "ssh-cli", "credential": "abc123"}; len(items) != 1 || fmt.Sprint(items[0].attributes) != fmt.Sprint(want) {
+		t.Errorf("items = %v, want one with attributes %v", items, want)
+	}
+
+	// Storing again replaces the secret instead of adding a second item
+	if err := ss.Set("abc123", "ssh-cli: admin@db", "correct horse"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	got, _ := ss.Get("abc123")
+	if items, _ := fake.state(); got != "correct horse" || len(items) != 1 {
+		t.Errorf("after replacing: Get = %q with %d items, want correct horse in 1 item", got, len(items))
+	}
+
+	if err := ss.Delete("abc123"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := ss.Get("abc123"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestSecretServiceMissingItem(t *testing.T) {
+	ss, _ := startSecretService(t, false)
+
+	if _, err := ss.Get("nope"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get = %v, want ErrSecretNotFound", err)
+	}
+	if err := ss.Delete("nope"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Delete = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestSecretServiceLockedCollection(t *testing.T) {
+	ss, fake := startSecretService(t, true)
+
+	// Storing unlocks the collection through a prompt first
+	if err := ss.Set("abc123", "ssh-cli: admin@db", "hunter2"); err != nil {
+		t.Fatalf("Set on a locked collection: %v", err)
+	}
+	if _, prompts := fake.state(); prompts != 1 {
+		t.Errorf("prompts = %d, want 1", prompts)
+	}
+
+	// An item found locked is unlocked before it is read
+	fake.lock()
+	if got, err := ss.Get("abc123"); err != nil || got != "hunter2" {
+		t.Fatalf("Get from a locked collection = %q, %v; want hunter2", got, err)
+	}
+	if _, prompts := fake.state(); prompts != 2 {
+		t.Errorf("prompts = %d, want 2", prompts)
+	}
+}
@@ -0,0 +1,105 @@
+package credential
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseSSHOption splits an ssh option given as Key=Value or "Key Value"
+func ParseSSHOption(option string) (string, string, error) {
+	option = strings.TrimSpace(option)
+	key, value, ok := strings.Cut(option, "=")
+	if !ok {
+		key, value, ok = strings.Cut(option, " ")
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	if !ok || key == "" || value == "" || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("invalid ssh option %q: expected Key=Value", option)
+	}
+	return key, value, nil
+}
+
+// MergeSSHOptions combines option sets, later sets winning. Option names are
+// case-insensitive in ssh, so ServerAliveInterval and serveraliveinterval are
+// the same option.
+func MergeSSHOptions(sets ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	names := make(map[string]string)
+	for _, set := range sets {
+		for key, value := range set {
+			lower := strings.ToLower(key)
+			if previous, ok := names[lower]; ok {
+				delete(merged, previous)
+			}
+			names[lower] = key
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// SetSSHOption adds or replaces an option, matching the name case-insensitively
+func (c *SSHCredential) SetSSHOption(key, value string) {
+	c.RemoveSSHOption(key)
+	if c.SSHOptions == nil {
+		c.SSHOptions = make(map[string]string)
+	}
+	c.SSHOptions[key] = value
+}
+
+// RemoveSSHOption deletes an option, matching the name case-insensitively
+func (c *SSHCredential) RemoveSSHOption(key string) {
+	for existing := range c.SSHOptions {
+		if strings.EqualFold(existing, key) {
+			delete(c.SSHOptions, existing)
+		}
+	}
+	if len(c.SSHOptions) == 0 {
+		c.SSHOptions = nil
+	}
+}
+
+// SSHOptionArgs turns options into -o arguments in a stable order
+func SSHOptionArgs(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		args = append(args, "-o", key+"="+options[key])
+	}
+	return args
+}
+
+// sshValueFlags are the ssh options that take a separate argument
+const sshValueFlags = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// HasRemoteCommand reports whether ssh arguments given after the destination
+// contain a remote command rather than only further options
+func HasRemoteCommand(args []string) bool {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return i+1 < len(args)
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return true
+		}
+		// In a group like -vL the first flag taking a value consumes the rest
+		// of the group, or the next argument if the group ends there
+		for j := 1; j < len(arg); j++ {
+			if strings.IndexByte(sshValueFlags, arg[j]) >= 0 {
+				if j == len(arg)-1 {
+					i++
+				}
+				break
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StrictPermissions makes commands refuse a store or key file that other
+// users can read or that another user owns, the way OpenSSH treats private
+// keys. When it is off such files only cause a warning.
+var StrictPermissions = true
+
+// PermissionError reports a file or directory that is not private to the user
+type PermissionError struct {
+	Path string
+	Mode fs.FileMode
+	// Readable is set when the group or others have any access
+	Readable bool
+	// Owner is the uid of a file owned by another user, or -1
+	Owner int
+}
+
+func (e *PermissionError) Error() string {
+	var reasons []string
+	if e.Readable {
+		reasons = append(reasons, fmt.Sprintf("is accessible by other users (permissions %04o)", e.Mode))
+	}
+	if e.Owner >= 0 {
+		reasons = append(reasons, fmt.Sprintf("is owned by another user (uid %d)", e.Owner))
+	}
+	return e.Path + " " + strings.Join(reasons, " and ")
+}
+
+// CheckPermissions returns a *PermissionError when path can be read by the
+// group or others, or is owned by someone other than the user or root. A
+// missing path is not an error; there is nothing to protect yet.
+func CheckPermissions(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e := &PermissionError{Path: path, Mode: info.Mode().Perm(), Owner: -1}
+	e.Readable = insecureMode(e.Mode)
+	if owner, ok := foreignOwner(info); ok {
+		e.Owner = owner
+	}
+	if !e.Readable && e.Owner < 0 {
+		return nil
+	}
+	return e
+}
+
+// CheckStorePermissions checks the store directory and file
+func CheckStorePermissions() ([]error, error) {
+	path, err := defaultStorePath()
+	if err != nil {
+		return nil, err
+	}
+	var problems []error
+	for _, p := range []string{filepath.Dir(path), path} {
+		if err := CheckPermissions(p); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package credential
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+func insecureMode(mode fs.FileMode) bool {
+	return mode&0077 != 0
+}
+
+// foreignOwner returns the owner of a file that belongs to neither the
+// current user nor root
+func foreignOwner(info fs.FileInfo) (int, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	owner := int(st.Uid)
+	return owner, owner != os.Getuid() && owner != 0
+}
@@ -0,0 +1,14 @@
+package credential
+
+import "io/fs"
+
+// Windows permissions are ACLs that the mode bits do not describe, so they
+// are not checked
+
+func insecureMode(fs.FileMode) bool {
+	return false
+}
+
+func foreignOwner(fs.FileInfo) (int, bool) {
+	return 0, false
+}
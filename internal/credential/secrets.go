@@ -0,0 +1,146 @@
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretProvider resolves a secret reference such as vault://secret/data/db#password
+type SecretProvider interface {
+	Resolve(ref *url.URL) (string, error)
+}
+
+var secretProviders = map[string]SecretProvider{
+	"keyring": keyringProvider{},
+	"vault":   &VaultProvider{},
+	"file":    fileProvider{},
+	"env":     envProvider{},
+}
+
+// RegisterSecretProvider adds or replaces the provider for a URI scheme
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProviders[scheme] = provider
+}
+
+func parseSecretRef(ref string) (*url.URL, SecretProvider, error) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid secret reference %q: %w", ref, err)
+	}
+	provider, ok := secretProviders[u.Scheme]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported secret reference %q: use keyring://, vault://, file:// or env://", ref)
+	}
+	return u, provider, nil
+}
+
+// ValidateSecretRef checks that a reference is well formed without resolving it
+func ValidateSecretRef(ref string) error {
+	u, _, err := parseSecretRef(ref)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" && u.Path == "" {
+		return fmt.Errorf("secret reference %q has no path", ref)
+	}
+	return nil
+}
+
+// ResolveSecret fetches the secret a reference points at
+func ResolveSecret(ref string) (string, error) {
+	u, provider, err := parseSecretRef(ref)
+	if err != nil {
+		return "", err
+	}
+	secret, err := provider.Resolve(u)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return secret, nil
+}
+
+// ResolvePassword returns the credential's password, resolving its reference if it has one
+func (c *SSHCredential) ResolvePassword() (string, error) {
+	if c.Password != "" || c.PasswordRef == "" {
+		return c.Password, nil
+	}
+	return ResolveSecret(c.PasswordRef)
+}
+
+// ResolveKeyPath returns a private key path usable by ssh. When the key comes from a
+// reference it is written to a private file under the store directory, which
+// cleanup removes.
+func (c *SSHCredential) ResolveKeyPath() (path string, cleanup func(), err error) {
+	if c.KeyRef == "" {
+		return c.KeyPath, func() {}, nil
+	}
+
+	key, err := ResolveSecret(c.KeyRef)
+	if err != nil {
+		return "", nil, err
+	}
+	// OpenSSH rejects private keys without a trailing newline
+	if !strings.HasSuffix(key, "\n") {
+		key += "\n"
+	}
+
+	nonce, err := GenerateID()
+	if err != nil {
+		return "", nil, err
+	}
+	return WritePrivateFile("key-"+nonce, key)
+}
+
+type keyringProvider struct{}
+
+func (keyringProvider) Resolve(ref *url.URL) (string, error) {
+	kr, err := OpenKeyring()
+	if err != nil {
+		return "", err
+	}
+	return kr.Get(ref.Host + ref.Path)
+}
+
+// fileProvider reads file:///abs/path or file://~/path
+type fileProvider struct{}
+
+// path returns the file a file:// reference points at
+func (fileProvider) path(ref *url.URL) (string, error) {
+	path := ref.Host + ref.Path
+	if strings.HasPrefix(path, "~") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+	}
+	return path, nil
+}
+
+func (p fileProvider) Resolve(ref *url.URL) (string, error) {
+	path, err := p.path(ref)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// envProvider reads env://VAR
+type envProvider struct{}
+
+func (envProvider) Resolve(ref *url.URL) (string, error) {
+	name := ref.Host + ref.Path
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", errors.New("environment variable " + name + " is not set")
+	}
+	return value, nil
+}
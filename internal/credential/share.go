@@ -0,0 +1,209 @@
+package credential
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// SharedCredential is the part of a credential that is shared with a team:
+// no secrets and nothing specific to one machine, such as key paths
+type SharedCredential struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Host     string            `json:"host"`
+	Port     int               `json:"port"`
+	Username string            `json:"username"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// SharedFields are the fields merged by sync, in display order
+var SharedFields = []string{"name", "host", "port", "username", "tags"}
+
+// Shared returns the shareable fields of a credential
+func (c SSHCredential) Shared() SharedCredential {
+	return SharedCredential{
+		ID:       c.ID,
+		Name:     c.Name,
+		Host:     c.Host,
+		Port:     c.Port,
+		Username: c.Username,
+		Tags:     c.Tags,
+	}
+}
+
+// ApplyShared copies the shared fields onto a credential, keeping its secrets
+// and local settings
+func (c *SSHCredential) ApplyShared(s SharedCredential) {
+	c.Name = s.Name
+	c.Host = s.Host
+	c.Port = s.Port
+	c.Username = s.Username
+	c.Tags = s.Tags
+}
+
+func (s SharedCredential) field(name string) string {
+	switch name {
+	case "name":
+		return s.Name
+	case "host":
+		return s.Host
+	case "port":
+		return strconv.Itoa(s.Port)
+	case "username":
+		return s.Username
+	case "tags":
+		return FormatTags(s.Tags)
+	}
+	return ""
+}
+
+func (s *SharedCredential) copyField(name string, from SharedCredential) {
+	switch name {
+	case "name":
+		s.Name = from.Name
+	case "host":
+		s.Host = from.Host
+	case "port":
+		s.Port = from.Port
+	case "username":
+		s.Username = from.Username
+	case "tags":
+		s.Tags = from.Tags
+	}
+}
+
+// SyncConflict is an entry changed differently on both sides since the last
+// sync. Field is empty when one side deleted an entry the other changed.
+type SyncConflict struct {
+	ID     string
+	Name   string
+	Field  string
+	Local  string
+	Remote string
+
+	remote *SharedCredential
+}
+
+func (c SyncConflict) String() string {
+	if c.Field == "" {
+		return fmt.Sprintf("%s: local %s, remote %s", c.Name, c.Local, c.Remote)
+	}
+	return fmt.Sprintf("%s.%s: local %q, remote %q", c.Name, c.Field, c.Local, c.Remote)
+}
+
+// SyncMerge is the result of merging local and remote entries against the
+// state of the last sync. Conflicts start out resolved in favour of local.
+type SyncMerge struct {
+	Conflicts []SyncConflict
+	entries   map[string]*SharedCredential
+}
+
+// MergeShared merges local and remote entries field by field, keyed on ID.
+// base is what both sides agreed on at the last sync; a side that left a field
+// as it was in base takes the other side's change.
+func MergeShared(base, local, remote []SharedCredential) *SyncMerge {
+	index := func(entries []SharedCredential) map[string]SharedCredential {
+		m := make(map[string]SharedCredential, len(entries))
+		for _, e := range entries {
+			m[e.ID] = e
+		}
+		return m
+	}
+	baseByID, localByID, remoteByID := index(base), index(local), index(remote)
+
+	ids := make(map[string]bool)
+	for _, m := range []map[string]SharedCredential{baseByID, localByID, remoteByID} {
+		for id := range m {
+			ids[id] = true
+		}
+	}
+
+	merge := &SyncMerge{entries: make(map[string]*SharedCredential)}
+	for id := range ids {
+		b, inBase := baseByID[id]
+		l, inLocal := localByID[id]
+		r, inRemote := remoteByID[id]
+
+		switch {
+		case inLocal && inRemote:
+			merged := l
+			for _, field := range SharedFields {
+				lv, rv, bv := l.field(field), r.field(field), b.field(field)
+				switch {
+				case lv == rv, inBase && rv == bv:
+				case inBase && lv == bv:
+					merged.copyField(field, r)
+				default:
+					remote := r
+					merge.Conflicts = append(merge.Conflicts, SyncConflict{ID: id, Name: l.Name, Field: field, Local: lv, Remote: rv, remote: &remote})
+				}
+			}
+			merge.entries[id] = &merged
+		case inLocal && !inBase:
+			merge.entries[id] = &l
+		case inRemote && !inBase:
+			merge.entries[id] = &r
+		case inLocal:
+			// Deleted remotely: follow unless changed locally
+			if l.differs(b) {
+				merge.entries[id] = &l
+				merge.Conflicts = append(merge.Conflicts, SyncConflict{ID: id, Name: l.Name, Local: "changed", Remote: "deleted"})
+			}
+		case inRemote:
+			// Deleted locally: follow unless changed remotely
+			if r.differs(b) {
+				remote := r
+				merge.Conflicts = append(merge.Conflicts, SyncConflict{ID: id, Name: r.Name, Local: "deleted", Remote: "changed", remote: &remote})
+			}
+		}
+	}
+
+	sort.Slice(merge.Conflicts, func(i, j int) bool {
+		if merge.Conflicts[i].Name != merge.Conflicts[j].Name {
+			return merge.Conflicts[i].Name < merge.Conflicts[j].Name
+		}
+		return merge.Conflicts[i].Field < merge.Conflicts[j].Field
+	})
+	return merge
+}
+
+// differs reports whether any shared field differs from other
+func (s SharedCredential) differs(other SharedCredential) bool {
+	for _, field := range SharedFields {
+		if s.field(field) != other.field(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// UseRemote resolves a conflict in favour of the remote side
+func (m *SyncMerge) UseRemote(c SyncConflict) {
+	switch {
+	case c.Field != "":
+		if entry := m.entries[c.ID]; entry != nil {
+			entry.copyField(c.Field, *c.remote)
+		}
+	case c.remote == nil:
+		delete(m.entries, c.ID)
+	default:
+		remote := *c.remote
+		m.entries[c.ID] = &remote
+	}
+}
+
+// Result returns the merged entries sorted by name and ID
+func (m *SyncMerge) Result() []SharedCredential {
+	result := make([]SharedCredential, 0, len(m.entries))
+	for _, entry := range m.entries {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,167 @@
+package credential
+
+import (
+	"reflect"
+	"testing"
+)
+
+func sharedEntry(id, name, host string, port int) SharedCredential {
+	return SharedCredential{ID: id, Name: name, Host: host, Port: port, Username: "root"}
+}
+
+func TestMergeShared(t *testing.T) {
+	web := sharedEntry("1", "web", "10.0.0.1", 22)
+	db := sharedEntry("2", "db", "10.0.0.2", 22)
+
+	withHost := func(e SharedCredential, host string) SharedCredential { e.Host = host; return e }
+	withPort := func(e SharedCredential, port int) SharedCredential { e.Port = port; return e }
+	withTags := func(e SharedCredential, tags map[string]string) SharedCredential { e.Tags = tags; return e }
+
+	tests := []struct {
+		name   string
+		base   []SharedCredential
+		local  []SharedCredential
+		remote []SharedCredential
+		// conflicts are the String() of each conflict
+		conflicts []string
+		// want is the result with conflicts left to local, wantRemote the
+		// result after resolving every conflict with UseRemote
+		want       []SharedCredential
+		wantRemote []SharedCredential
+	}{
+		{
+			name:   "unchanged",
+			base:   []SharedCredential{web, db},
+			local:  []SharedCredential{web, db},
+			remote: []SharedCredential{web, db},
+			want:   []SharedCredential{db, web},
+		},
+		{
+			name:   "changed locally",
+			base:   []SharedCredential{web},
+			local:  []SharedCredential{withHost(web, "10.0.0.9")},
+			remote: []SharedCredential{web},
+			want:   []SharedCredential{withHost(web, "10.0.0.9")},
+		},
+		{
+			name:   "changed remotely",
+			base:   []SharedCredential{web},
+			local:  []SharedCredential{web},
+			remote: []SharedCredential{withPort(web, 2222)},
+			want:   []SharedCredential{withPort(web, 2222)},
+		},
+		{
+			name:   "different fields changed on each side",
+			base:   []SharedCredential{web},
+			local:  []SharedCredential{withHost(web, "10.0.0.9")},
+			remote: []SharedCredential{withPort(web, 2222)},
+			want:   []SharedCredential{withPort(withHost(web, "10.0.0.9"), 2222)},
+		},
+		{
+			name:   "same change on both sides",
+			base:   []SharedCredential{web},
+			local:  []SharedCredential{withHost(web, "10.0.0.9")},
+			remote: []SharedCredential{withHost(web, "10.0.0.9")},
+			want:   []SharedCredential{withHost(web, "10.0.0.9")},
+		},
+		{
+			name:       "same field changed on both sides",
+			base:       []SharedCredential{web},
+			local:      []SharedCredential{withHost(web, "10.0.0.8")},
+			remote:     []SharedCredential{withPort(withHost(web, "10.0.0.9"), 2222)},
+			conflicts:  []string{`web.host: local "10.0.0.8", remote "10.0.0.9"`},
+			want:       []SharedCredential{withPort(withHost(web, "10.0.0.8"), 2222)},
+			wantRemote: []SharedCredential{withPort(withHost(web, "10.0.0.9"), 2222)},
+		},
+		{
+			name:       "tags changed on both sides",
+			base:       []SharedCredential{web},
+			local:      []SharedCredential{withTags(web, map[string]string{"env": "prod"})},
+			remote:     []SharedCredential{withTags(web, map[string]string{"env": "staging"})},
+			conflicts:  []string{`web.tags: local "env=prod", remote "env=staging"`},
+			want:       []SharedCredential{withTags(web, map[string]string{"env": "prod"})},
+			wantRemote: []SharedCredential{withTags(web, map[string]string{"env": "staging"})},
+		},
+		{
+			name:   "deleted locally",
+			base:   []SharedCredential{web, db},
+			local:  []SharedCredential{web},
+			remote: []SharedCredential{web, db},
+			want:   []SharedCredential{web},
+		},
+		{
+			name:   "deleted remotely",
+			base:   []SharedCredential{web, db},
+			local:  []SharedCredential{web, db},
+			remote: []SharedCredential{db},
+			want:   []SharedCredential{db},
+		},
+		{
+			name:       "deleted locally and changed remotely",
+			base:       []SharedCredential{web},
+			local:      nil,
+			remote:     []SharedCredential{withHost(web, "10.0.0.9")},
+			conflicts:  []string{"web: local deleted, remote changed"},
+			want:       []SharedCredential{},
+			wantRemote: []SharedCredential{withHost(web, "10.0.0.9")},
+		},
+		{
+			name:       "changed locally and deleted remotely",
+			base:       []SharedCredential{web},
+			local:      []SharedCredential{withHost(web, "10.0.0.9")},
+			remote:     nil,
+			conflicts:  []string{"web: local changed, remote deleted"},
+			want:       []SharedCredential{withHost(web, "10.0.0.9")},
+			wantRemote: []SharedCredential{},
+		},
+		{
+			name:   "added on one side",
+			local:  []SharedCredential{web},
+			remote: []SharedCredential{db},
+			want:   []SharedCredential{db, web},
+		},
+		{
+			name:   "added on both sides alike",
+			local:  []SharedCredential{web},
+			remote: []SharedCredential{web},
+			want:   []SharedCredential{web},
+		},
+		{
+			name:   "added on both sides differently",
+			local:  []SharedCredential{web},
+			remote: []SharedCredential{withPort(withHost(web, "10.0.0.9"), 2222)},
+			conflicts: []string{
+				`web.host: local "10.0.0.1", remote "10.0.0.9"`,
+				`web.port: local "22", remote "2222"`,
+			},
+			want:       []SharedCredential{web},
+			wantRemote: []SharedCredential{withPort(withHost(web, "10.0.0.9"), 2222)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merge := MergeShared(tt.base, tt.local, tt.remote)
+			var conflicts []string
+			for _, c := range merge.Conflicts {
+				conflicts = append(conflicts, c.String())
+			}
+			if !reflect.DeepEqual(conflicts, tt.conflicts) {
+				t.Fatalf("conflicts = %q, want %q", conflicts, tt.conflicts)
+			}
+			if got := merge.Result(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("result = %+v, want %+v", got, tt.want)
+			}
+
+			if len(tt.conflicts) == 0 {
+				return
+			}
+			merge = MergeShared(tt.base, tt.local, tt.remote)
+			for _, c := range merge.Conflicts {
+				merge.UseRemote(c)
+			}
+			if got := merge.Result(); !reflect.DeepEqual(got, tt.wantRemote) {
+				t.Errorf("result with remote = %+v, want %+v", got, tt.wantRemote)
+			}
+		})
+	}
+}
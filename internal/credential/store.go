@@ -6,10 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type CredentialStore struct {
 	Credentials []SSHCredential `json:"credentials"`
+	Trash       []SSHCredential `json:"trash,omitempty"`
 	filepath    string
 }
 
@@ -23,13 +25,34 @@ func (s *CredentialStore) FindCredentialsByName(name string) []SSHCredential {
 	return matches
 }
 
-func NewCredentialStore() (*CredentialStore, error) {
+// defaultStorePath returns where the credentials file lives
+func defaultStorePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh-cred-manager", "credentials.json"), nil
+}
+
+// ReadCredentialStore loads the store without creating it or purging the
+// trash. It never writes or resolves secrets, so shell completion can use it.
+func ReadCredentialStore() (*CredentialStore, error) {
+	storePath, err := defaultStorePath()
+	if err != nil {
+		return nil, err
+	}
+	store := &CredentialStore{filepath: storePath}
+	if err := store.load(); err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
+	return store, nil
+}
 
-	storePath := filepath.Join(homeDir, ".ssh-cred-manager", "credentials.json")
+func NewCredentialStore() (*CredentialStore, error) {
+	storePath, err := defaultStorePath()
+	if err != nil {
+		return nil, err
+	}
 
 	if err := os.MkdirAll(filepath.Dir(storePath), 0700); err != nil {
 		return nil, err
@@ -41,8 +64,18 @@ func NewCredentialStore() (*CredentialStore, error) {
 
 	if _, err := os.Stat(storePath); !os.IsNotExist(err) {
 		if err := store.load(); err != nil {
+			return nil, fmt.Errorf("%w (run 'ssh-cli store doctor' for details)", err)
+		}
+	}
+
+	if purged := store.purgeTrash(time.Now().Add(-TrashRetention())); len(purged) > 0 {
+		if err := store.save(); err != nil {
 			return nil, err
 		}
+		forgetSecrets(purged)
+		for _, cred := range purged {
+			store.auditChange(AuditPurge, cred, nil)
+		}
 	}
 
 	return store, nil
@@ -56,12 +89,20 @@ func (s *CredentialStore) SaveCredential(cred SSHCredential) error {
 	for i, existing := range s.Credentials {
 		if existing.Name == cred.Name {
 			s.Credentials[i] = cred
-			return s.save()
+			if err := s.save(); err != nil {
+				return err
+			}
+			s.auditChange(AuditUpdate, cred, ChangedFields(existing, cred))
+			return nil
 		}
 	}
 
 	s.Credentials = append(s.Credentials, cred)
-	return s.save()
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.auditChange(AuditSave, cred, nil)
+	return nil
 }
 
 func (s *CredentialStore) load() error {
@@ -97,19 +138,40 @@ func (s *CredentialStore) GetCredential(name string) (*SSHCredential, error) {
 	return nil, fmt.Errorf("credential not found: %s", name)
 }
 
-// DeleteCredential removes a credential by name
+// FindCredential retrieves a credential by name, or failing that by ID
+func (s *CredentialStore) FindCredential(nameOrID string) (*SSHCredential, error) {
+	if cred, err := s.GetCredential(nameOrID); err == nil {
+		return cred, nil
+	}
+	for _, cred := range s.Credentials {
+		if cred.ID == nameOrID {
+			return &cred, nil
+		}
+	}
+	return nil, fmt.Errorf("credential not found: %s", nameOrID)
+}
+
+// DeleteCredential moves a credential to the trash by name
 func (s *CredentialStore) DeleteCredential(name string) error {
 	for i, cred := range s.Credentials {
 		if cred.Name == name {
-			// Remove the credential from the slice
+			// Remove the credential from the slice and keep it in the trash
 			s.Credentials = append(s.Credentials[:i], s.Credentials[i+1:]...)
-			return s.save()
+			now := time.Now()
+			cred.DeletedAt = &now
+			s.Trash = append(s.Trash, cred)
+			if err := s.save(); err != nil {
+				return err
+			}
+			s.auditChange(AuditDelete, cred, nil)
+			return nil
 		}
 	}
 	return fmt.Errorf("credential not found: %s", name)
 }
 
-// UpdateCredential updates an existing credential
+// UpdateCredential updates an existing credential. A keyring entry the old
+// password lived in is removed once nothing refers to it any more.
 func (s *CredentialStore) UpdateCredential(name string, cred SSHCredential) error {
 	if err := cred.Validate(); err != nil {
 		return err
@@ -118,7 +180,14 @@ func (s *CredentialStore) UpdateCredential(name string, cred SSHCredential) erro
 	for i, existing := range s.Credentials {
 		if existing.Name == name {
 			s.Credentials[i] = cred
-			return s.save()
+			if err := s.save(); err != nil {
+				return err
+			}
+			s.auditChange(AuditUpdate, cred, ChangedFields(existing, cred))
+			if existing.PasswordRef != cred.PasswordRef {
+				forgetSecrets([]SSHCredential{existing})
+			}
+			return nil
 		}
 	}
 	return fmt.Errorf("credential not found: %s", name)
@@ -0,0 +1,159 @@
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncChanges lists the credential names changed locally by a sync
+type SyncChanges struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// SyncDir is the git working copy used to share credentials
+func (s *CredentialStore) SyncDir() string {
+	return filepath.Join(filepath.Dir(s.filepath), "sync")
+}
+
+func (s *CredentialStore) syncBasePath() string {
+	return filepath.Join(filepath.Dir(s.filepath), "sync-base.json")
+}
+
+// SharedCredentials returns the shareable part of every credential
+func (s *CredentialStore) SharedCredentials() []SharedCredential {
+	shared := make([]SharedCredential, 0, len(s.Credentials))
+	for _, cred := range s.Credentials {
+		shared = append(shared, cred.Shared())
+	}
+	return shared
+}
+
+// syncBase is what both sides agreed on at the last sync with Remote
+type syncBase struct {
+	Remote  string             `json:"remote"`
+	Entries []SharedCredential `json:"entries"`
+}
+
+// LoadSyncBase returns the entries as of the last successful sync with remote.
+// A base recorded for another repository, or before repositories were
+// recorded, says nothing about remote, so syncing starts from an empty base.
+func (s *CredentialStore) LoadSyncBase(remote string) ([]SharedCredential, error) {
+	data, err := os.ReadFile(s.syncBasePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && data[0] == '[' {
+		return nil, nil
+	}
+	var base syncBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", s.syncBasePath(), err)
+	}
+	if base.Remote != remote {
+		return nil, nil
+	}
+	return base.Entries, nil
+}
+
+// SaveSyncBase remembers the entries both sides agree on after a sync with remote
+func (s *CredentialStore) SaveSyncBase(remote string, entries []SharedCredential) error {
+	data, err := json.MarshalIndent(syncBase{Remote: remote, Entries: entries}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.syncBasePath(), data, 0600)
+}
+
+// UniqueSharedNames renames entries whose name is already taken by another
+// entry, appending part of the ID, and returns the new names
+func UniqueSharedNames(entries []SharedCredential) []string {
+	var renamed []string
+	seen := make(map[string]bool)
+	for i := range entries {
+		if seen[entries[i].Name] {
+			suffix := entries[i].ID
+			if len(suffix) > 6 {
+				suffix = suffix[:6]
+			}
+			entries[i].Name = fmt.Sprintf("%s-%s", entries[i].Name, suffix)
+			renamed = append(renamed, entries[i].Name)
+		}
+		seen[entries[i].Name] = true
+	}
+	return renamed
+}
+
+// ApplySync makes the local credentials match the merged entries. Existing
+// credentials keep their secrets and local settings; new ones start from
+// template. Credentials missing from entries are moved to the trash.
+// Synced entries are taken as they are and not validated, since a teammate's
+// entry may need a local key before it can be used.
+func (s *CredentialStore) ApplySync(entries []SharedCredential, template SSHCredential) (SyncChanges, error) {
+	var changes SyncChanges
+	wanted := make(map[string]SharedCredential, len(entries))
+	for _, entry := range entries {
+		wanted[entry.ID] = entry
+	}
+
+	type audit struct {
+		action AuditAction
+		cred   SSHCredential
+		fields []string
+	}
+	var audits []audit
+	now := time.Now()
+
+	kept := s.Credentials[:0]
+	for _, cred := range s.Credentials {
+		entry, ok := wanted[cred.ID]
+		if !ok {
+			cred.DeletedAt = &now
+			s.Trash = append(s.Trash, cred)
+			changes.Removed = append(changes.Removed, cred.Name)
+			audits = append(audits, audit{AuditDelete, cred, nil})
+			continue
+		}
+		delete(wanted, cred.ID)
+
+		updated := cred
+		updated.ApplyShared(entry)
+		if fields := ChangedFields(cred, updated); len(fields) > 0 {
+			updated.UpdatedAt = now
+			changes.Updated = append(changes.Updated, updated.Name)
+			audits = append(audits, audit{AuditUpdate, updated, fields})
+		}
+		kept = append(kept, updated)
+	}
+	s.Credentials = kept
+
+	for _, entry := range entries {
+		if _, ok := wanted[entry.ID]; !ok {
+			continue
+		}
+		cred := template
+		cred.ID = entry.ID
+		cred.ApplyShared(entry)
+		cred.CreatedAt = now
+		cred.UpdatedAt = now
+		s.Credentials = append(s.Credentials, cred)
+		changes.Added = append(changes.Added, cred.Name)
+		audits = append(audits, audit{AuditSave, cred, nil})
+	}
+
+	if err := s.save(); err != nil {
+		return changes, err
+	}
+	for _, a := range audits {
+		s.auditChange(a.action, a.cred, a.fields)
+	}
+	return changes, nil
+}
@@ -0,0 +1,132 @@
+package credential
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Target is the user, host and port of a connection string
+type Target struct {
+	User string
+	Host string
+	Port int
+}
+
+// ParseTarget parses a connection string:
+//
+//	host
+//	user@host:port
+//	user@[2001:db8::1]:2222
+//	ssh://user@host:port
+//
+// The user is everything before the last @, so it may contain @ itself; in
+// the ssh:// form it may also be percent-encoded. A bare IPv6 address is
+// taken as a host; put it in brackets to add a port. A missing user or port
+// is filled from defaultUser and defaultPort.
+func ParseTarget(s, defaultUser string, defaultPort int) (Target, error) {
+	t := Target{User: defaultUser, Port: defaultPort}
+	value := strings.TrimSpace(s)
+
+	uri := false
+	if scheme, rest, ok := strings.Cut(value, "://"); ok {
+		if !strings.EqualFold(scheme, "ssh") {
+			return Target{}, fmt.Errorf("invalid connection string %q: only ssh:// URIs are supported", s)
+		}
+		value = strings.TrimSuffix(rest, "/")
+		if strings.ContainsAny(value, "/?#") {
+			return Target{}, fmt.Errorf("invalid connection string %q: unexpected path or query", s)
+		}
+		uri = true
+	}
+
+	if at := strings.LastIndex(value, "@"); at != -1 {
+		user := value[:at]
+		value = value[at+1:]
+		if uri {
+			// ssh://user;fingerprint=...@host carries a host key fingerprint
+			user, _, _ = strings.Cut(user, ";")
+			var err error
+			if user, err = url.PathUnescape(user); err != nil {
+				return Target{}, fmt.Errorf("invalid user in %q: %w", s, err)
+			}
+		}
+		if user == "" {
+			return Target{}, fmt.Errorf("invalid connection string %q: empty user before @", s)
+		}
+		t.User = user
+	}
+
+	host, port := value, ""
+	switch {
+	case strings.HasPrefix(value, "["):
+		end := strings.Index(value, "]")
+		if end == -1 {
+			return Target{}, fmt.Errorf("invalid connection string %q: missing ]", s)
+		}
+		host, port = value[1:end], value[end+1:]
+		if port != "" {
+			var ok bool
+			if port, ok = strings.CutPrefix(port, ":"); !ok {
+				return Target{}, fmt.Errorf("invalid connection string %q: unexpected %q after ]", s, port)
+			}
+			if port == "" {
+				return Target{}, fmt.Errorf("invalid connection string %q: missing port after :", s)
+			}
+		}
+	case strings.Count(value, ":") == 1:
+		host, port, _ = strings.Cut(value, ":")
+		if port == "" {
+			return Target{}, fmt.Errorf("invalid connection string %q: missing port after :", s)
+		}
+	}
+
+	if host == "" {
+		return Target{}, fmt.Errorf("invalid connection string %q: missing host", s)
+	}
+	if strings.ContainsAny(host, " \t[]@/") {
+		return Target{}, fmt.Errorf("invalid host %q in %q", host, s)
+	}
+	t.Host = host
+
+	if port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil || p <= 0 || p > 65535 {
+			return Target{}, fmt.Errorf("invalid port %q in %q: must be between 1 and 65535", port, s)
+		}
+		t.Port = p
+	}
+	return t, nil
+}
+
+// String formats the target as user@host:port, with IPv6 hosts in brackets
+func (t Target) String() string {
+	host := t.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if t.User != "" {
+		host = t.User + "@" + host
+	}
+	if t.Port != 0 {
+		host += ":" + strconv.Itoa(t.Port)
+	}
+	return host
+}
+
+// FindByTarget returns the credentials that connect to a target. An empty
+// user or a zero port matches any.
+func (s *CredentialStore) FindByTarget(t Target) []SSHCredential {
+	var matches []SSHCredential
+	for _, cred := range s.Credentials {
+		if !strings.EqualFold(cred.Host, t.Host) {
+			continue
+		}
+		if (t.User != "" && cred.Username != t.User) || (t.Port != 0 && cred.Port != t.Port) {
+			continue
+		}
+		matches = append(matches, cred)
+	}
+	return matches
+}
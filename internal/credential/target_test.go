@@ -0,0 +1,92 @@
+package credential
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Target
+		// err is a substring of the error, empty when parsing succeeds
+		err string
+	}{
+		{in: "example.com", want: Target{User: "root", Host: "example.com", Port: 22}},
+		{in: " deploy@example.com ", want: Target{User: "deploy", Host: "example.com", Port: 22}},
+		{in: "deploy@example.com:2222", want: Target{User: "deploy", Host: "example.com", Port: 2222}},
+		{in: "me@corp@example.com", want: Target{User: "me@corp", Host: "example.com", Port: 22}},
+		{in: "10.0.0.1:2222", want: Target{User: "root", Host: "10.0.0.1", Port: 2222}},
+
+		{in: "[::1]:22", want: Target{User: "root", Host: "::1", Port: 22}},
+		{in: "[::1]", want: Target{User: "root", Host: "::1", Port: 22}},
+		{in: "deploy@[2001:db8::1]:2222", want: Target{User: "deploy", Host: "2001:db8::1", Port: 2222}},
+		{in: "::1", want: Target{User: "root", Host: "::1", Port: 22}},
+		{in: "deploy@2001:db8::1", want: Target{User: "deploy", Host: "2001:db8::1", Port: 22}},
+		{in: "fe80::1%eth0", want: Target{User: "root", Host: "fe80::1%eth0", Port: 22}},
+
+		{in: "ssh://u@h:2200", want: Target{User: "u", Host: "h", Port: 2200}},
+		{in: "ssh://h", want: Target{User: "root", Host: "h", Port: 22}},
+		{in: "SSH://u@h/", want: Target{User: "u", Host: "h", Port: 22}},
+		{in: "ssh://me%40corp@h", want: Target{User: "me@corp", Host: "h", Port: 22}},
+		{in: "ssh://u;fingerprint=SHA256:abc@h:22", want: Target{User: "u", Host: "h", Port: 22}},
+		{in: "ssh://u@[2001:db8::1]:2222", want: Target{User: "u", Host: "2001:db8::1", Port: 2222}},
+
+		{in: "h:0", err: "invalid port"},
+		{in: "h:65536", err: "invalid port"},
+		{in: "h:ssh", err: "invalid port"},
+		{in: "h:-1", err: "invalid port"},
+		{in: "h:", err: "missing port"},
+		{in: "[::1]:", err: "missing port"},
+		{in: "[::1]:x", err: "invalid port"},
+		{in: "[::1]22", err: "unexpected"},
+		{in: "[::1", err: "missing ]"},
+		{in: "", err: "missing host"},
+		{in: "u@", err: "missing host"},
+		{in: "@h", err: "empty user"},
+		{in: "ssh://u@h/path", err: "unexpected path"},
+		{in: "ssh://u@h?x=1", err: "unexpected path"},
+		{in: "ssh://%zz@h", err: "invalid user"},
+		{in: "sftp://u@h", err: "only ssh://"},
+		{in: "bad host", err: "invalid host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTarget(tt.in, "root", 22)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseTarget(%q) error = %v, want %q", tt.in, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTarget(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTarget(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetString(t *testing.T) {
+	tests := []struct {
+		target Target
+		want   string
+	}{
+		{Target{User: "root", Host: "example.com", Port: 22}, "root@example.com:22"},
+		{Target{User: "deploy", Host: "2001:db8::1", Port: 2222}, "deploy@[2001:db8::1]:2222"},
+		{Target{Host: "example.com"}, "example.com"},
+	}
+	for _, tt := range tests {
+		if got := tt.target.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.target, got, tt.want)
+		}
+		// The string form parses back to the same target
+		if tt.target.User != "" {
+			if got, err := ParseTarget(tt.want, "", 0); err != nil || got != tt.target {
+				t.Errorf("ParseTarget(%q) = %+v, %v, want %+v", tt.want, got, err, tt.target)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package credential
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PrivateFilePath returns where the short-lived secret file name lives: in
+// tmp next to the credentials file, which only the user can enter, rather
+// than in the shared temporary directory
+func PrivateFilePath(name string) (string, error) {
+	storePath, err := defaultStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(storePath), "tmp", name), nil
+}
+
+// WritePrivateFile writes a secret, such as a resolved key or a password for
+// ssh's askpass, to the new file PrivateFilePath(name). cleanup removes it.
+func WritePrivateFile(name, data string) (path string, cleanup func(), err error) {
+	path, err = PrivateFilePath(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.Remove(path) }
+	if _, err := f.WriteString(data); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return path, cleanup, nil
+}
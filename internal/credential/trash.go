@@ -0,0 +1,78 @@
+package credential
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTrashRetention is how long deleted credentials are kept unless the
+// commands configure another retention
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashRetention returns how long deleted credentials are kept before being
+// purged. It is a variable so the commands can plug in the configured
+// retention, which is only looked up when the trash is purged.
+var TrashRetention = func() time.Duration {
+	return DefaultTrashRetention
+}
+
+// ListTrash returns all credentials in the trash
+func (s *CredentialStore) ListTrash() []SSHCredential {
+	return s.Trash
+}
+
+// RestoreCredential moves the most recently deleted credential with the given name back from the trash
+func (s *CredentialStore) RestoreCredential(name string) error {
+	// Trash is appended in deletion order, so the last match is the most recent
+	idx := -1
+	for i, cred := range s.Trash {
+		if cred.Name == name {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("credential not found in trash: %s", name)
+	}
+
+	if existing, _ := s.GetCredential(name); existing != nil {
+		return fmt.Errorf("a credential with the name '%s' already exists", name)
+	}
+
+	cred := s.Trash[idx]
+	cred.DeletedAt = nil
+	s.Trash = append(s.Trash[:idx], s.Trash[idx+1:]...)
+	s.Credentials = append(s.Credentials, cred)
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.auditChange(AuditRestore, cred, nil)
+	return nil
+}
+
+// EmptyTrash permanently removes all credentials from the trash
+func (s *CredentialStore) EmptyTrash() error {
+	purged := s.Trash
+	s.Trash = nil
+	if err := s.save(); err != nil {
+		return err
+	}
+	forgetSecrets(purged)
+	for _, cred := range purged {
+		s.auditChange(AuditPurge, cred, nil)
+	}
+	return nil
+}
+
+// purgeTrash drops credentials deleted before the cutoff and returns the removed ones
+func (s *CredentialStore) purgeTrash(cutoff time.Time) []SSHCredential {
+	var kept, purged []SSHCredential
+	for _, cred := range s.Trash {
+		if cred.DeletedAt != nil && cred.DeletedAt.Before(cutoff) {
+			purged = append(purged, cred)
+			continue
+		}
+		kept = append(kept, cred)
+	}
+	s.Trash = kept
+	return purged
+}
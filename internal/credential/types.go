@@ -3,6 +3,9 @@ package credential
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,16 +17,46 @@ const (
 )
 
 type SSHCredential struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Host      string    `json:"host"`
-	Port      int       `json:"port"`
-	Username  string    `json:"username"`
-	AuthType  AuthType  `json:"auth_type"`
-	Password  string    `json:"password,omitempty"`
-	KeyPath   string    `json:"key_path,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Host        string   `json:"host"`
+	Port        int      `json:"port"`
+	Username    string   `json:"username"`
+	AuthType    AuthType `json:"auth_type"`
+	Password    string   `json:"password,omitempty"`
+	PasswordRef string   `json:"password_ref,omitempty"`
+	KeyPath     string   `json:"key_path,omitempty"`
+	KeyRef      string   `json:"key_ref,omitempty"`
+	KeyPassRef  string   `json:"key_passphrase_ref,omitempty"`
+	CertPath    string   `json:"cert_path,omitempty"`
+
+	Tags       map[string]string `json:"tags,omitempty"`
+	SSHOptions map[string]string `json:"ssh_options,omitempty"`
+	ExtraArgs  []string          `json:"extra_args,omitempty"`
+
+	RemoteCommand string `json:"remote_command,omitempty"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	UseCount   int        `json:"use_count,omitempty"`
+}
+
+// SetAuthType switches how a credential authenticates, clearing what only the
+// old type used so no stale password or key is kept, listed, exported or synced
+func (c *SSHCredential) SetAuthType(auth AuthType) {
+	if auth == c.AuthType {
+		return
+	}
+	c.AuthType = auth
+	switch auth {
+	case Password:
+		c.KeyPath, c.KeyRef, c.KeyPassRef, c.CertPath = "", "", "", ""
+	case KeyFile:
+		c.Password, c.PasswordRef = "", ""
+	}
 }
 
 // GenerateID creates a unique ID for the credential
@@ -34,3 +67,27 @@ func GenerateID() (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// ParseTag splits a key=value tag. A tag without a value is allowed.
+func ParseTag(tag string) (string, string, error) {
+	key, value, _ := strings.Cut(tag, "=")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", "", fmt.Errorf("invalid tag %q: expected key=value", tag)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// FormatTags returns the tags as sorted key=value pairs
+func FormatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		if value == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -17,26 +18,42 @@ func (c *SSHCredential) Validate() error {
 		return errors.New("host cannot be empty")
 	}
 
-	if _, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("%s:%d", c.Host, c.Port)); err != nil {
-		return fmt.Errorf("invalid host or port: %v", err)
-	}
-
 	if c.Port <= 0 || c.Port > 65535 {
 		return errors.New("port must be between 1 and 65535")
 	}
 
+	// Only the syntax is checked: a host may only resolve behind a jump host
+	// or on another network
+	target, err := ParseTarget(net.JoinHostPort(c.Host, strconv.Itoa(c.Port)), "", 0)
+	if err != nil || target.Host != c.Host {
+		return fmt.Errorf("invalid host %q", c.Host)
+	}
+
 	if strings.TrimSpace(c.Username) == "" {
 		return errors.New("username cannot be empty")
 	}
 
 	switch c.AuthType {
 	case Password:
-		if strings.TrimSpace(c.Password) == "" {
+		if strings.TrimSpace(c.Password) == "" && strings.TrimSpace(c.PasswordRef) == "" {
 			return errors.New("password cannot be empty when using password authentication")
 		}
+		if c.PasswordRef != "" {
+			if err := ValidateSecretRef(c.PasswordRef); err != nil {
+				return err
+			}
+		}
 	case KeyFile:
+		if c.KeyRef != "" {
+			// The key is resolved at connect time, so there is no file to check
+			if err := ValidateSecretRef(c.KeyRef); err != nil {
+				return err
+			}
+			break
+		}
 		if strings.TrimSpace(c.KeyPath) == "" {
-			return errors.New("key path cannot be empty when using key authentication")
+			// No key: ssh picks one from its agent and config
+			break
 		}
 		if _, err := os.Stat(c.KeyPath); os.IsNotExist(err) {
 			return errors.New("SSH key file does not exist")
@@ -45,5 +62,17 @@ func (c *SSHCredential) Validate() error {
 		return errors.New("invalid authentication type")
 	}
 
+	if c.KeyPassRef != "" {
+		if err := ValidateSecretRef(c.KeyPassRef); err != nil {
+			return err
+		}
+	}
+
+	if c.CertPath != "" {
+		if _, err := os.Stat(c.CertPath); os.IsNotExist(err) {
+			return errors.New("SSH certificate file does not exist")
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,138 @@
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads secrets from HashiCorp Vault over its HTTP API. A reference
+// looks like vault://secret/data/prod-db#password: the path is read with a GET
+// and the fragment selects the field. Both KV version 1 and 2 responses are
+// understood. Empty fields fall back to VAULT_ADDR, VAULT_TOKEN (or
+// ~/.vault-token) and VAULT_NAMESPACE.
+type VaultProvider struct {
+	Address   string
+	Token     string
+	Namespace string
+	Client    *http.Client
+}
+
+func (v *VaultProvider) token() (string, error) {
+	if v.Token != "" {
+		return v.Token, nil
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		if data, err := os.ReadFile(filepath.Join(homeDir, ".vault-token")); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	return "", errors.New("no Vault token: set VAULT_TOKEN or run 'vault login'")
+}
+
+func (v *VaultProvider) Resolve(ref *url.URL) (string, error) {
+	address := v.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return "", errors.New("no Vault address: set VAULT_ADDR")
+	}
+	token, err := v.token()
+	if err != nil {
+		return "", err
+	}
+	namespace := v.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+
+	path := strings.Trim(ref.Host+ref.Path, "/")
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(address, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		json.Unmarshal(body, &vaultErr)
+		if len(vaultErr.Errors) > 0 {
+			return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("invalid Vault response: %w", err)
+	}
+	data := payload.Data
+	// KV version 2 nests the secret under data.data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = nested
+		}
+	}
+
+	return vaultField(data, ref.Fragment)
+}
+
+func vaultField(data map[string]any, field string) (string, error) {
+	if field == "" {
+		if len(data) != 1 {
+			keys := make([]string, 0, len(data))
+			for key := range data {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			return "", fmt.Errorf("secret has several fields (%s): pick one with #field", strings.Join(keys, ", "))
+		}
+		for key := range data {
+			field = key
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field %q is not a string", field)
+	}
+	return s, nil
+}
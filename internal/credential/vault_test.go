@@ -0,0 +1,130 @@
+package credential
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// vaultStub serves a few secrets the way Vault's HTTP API does and checks
+// the request headers
+func vaultStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	t.Setenv("VAULT_NAMESPACE", "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("method = %s, want GET", r.Method)
+		}
+		if token := r.Header.Get("X-Vault-Token"); token != "s.test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if r.URL.Path == "/v1/team/data/db" && r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/secret/data/prod-db", "/v1/team/data/db":
+			// KV version 2
+			w.Write([]byte(`{"data":{"data":{"password":"hunter2","user":"admin"},"metadata":{"version":3}}}`))
+		case "/v1/kv/legacy":
+			// KV version 1
+			w.Write([]byte(`{"data":{"password":"v1-secret"}}`))
+		case "/v1/secret/data/numbers":
+			w.Write([]byte(`{"data":{"data":{"pin":1234},"metadata":{"version":1}}}`))
+		case "/v1/secret/data/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["1 error occurred:\n\t* permission denied\n\n"]}`))
+		case "/v1/secret/data/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func resolveVault(t *testing.T, v *VaultProvider, ref string) (string, error) {
+	t.Helper()
+	u, err := url.Parse(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.Resolve(u)
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := vaultStub(t)
+	v := &VaultProvider{Address: server.URL, Token: "s.test-token", Client: server.Client()}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "vault://secret/data/prod-db#password", want: "hunter2"},
+		{ref: "vault://secret/data/prod-db#user", want: "admin"},
+		{ref: "vault:///secret/data/prod-db/#password", want: "hunter2"},
+		{ref: "vault://kv/legacy#password", want: "v1-secret"},
+		{ref: "vault://kv/legacy", want: "v1-secret"},
+		{ref: "vault://secret/data/prod-db#missing", wantErr: `secret has no field "missing"`},
+		{ref: "vault://secret/data/prod-db", wantErr: "several fields (password, user)"},
+		{ref: "vault://secret/data/numbers#pin", wantErr: `field "pin" is not a string`},
+		{ref: "vault://secret/data/forbidden#password", wantErr: "403 Forbidden: 1 error occurred"},
+		{ref: "vault://secret/data/absent#password", wantErr: "vault returned 404 Not Found"},
+		{ref: "vault://secret/data/broken#password", wantErr: "vault returned 500"},
+	}
+	for _, tt := range tests {
+		got, err := resolveVault(t, v, tt.ref)
+		switch {
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error = %v, want one containing %q", tt.ref, err, tt.wantErr)
+		case tt.wantErr == "" && (err != nil || got != tt.want):
+			t.Errorf("%s = %q, %v; want %q", tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestVaultProviderToken(t *testing.T) {
+	server := vaultStub(t)
+	t.Setenv("HOME", t.TempDir())
+
+	// A wrong token is rejected by Vault
+	v := &VaultProvider{Address: server.URL, Token: "s.wrong", Client: server.Client()}
+	if _, err := resolveVault(t, v, "vault://secret/data/prod-db#password"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("wrong token: error = %v, want permission denied", err)
+	}
+
+	// The token and address fall back to the environment
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "s.test-token")
+	v = &VaultProvider{Client: server.Client()}
+	if got, err := resolveVault(t, v, "vault://secret/data/prod-db#password"); err != nil || got != "hunter2" {
+		t.Errorf("token from VAULT_TOKEN: %q, %v; want hunter2", got, err)
+	}
+
+	t.Setenv("VAULT_TOKEN", "")
+	if _, err := resolveVault(t, v, "vault://secret/data/prod-db#password"); err == nil || !strings.Contains(err.Error(), "no Vault token") {
+		t.Errorf("no token: error = %v, want no Vault token", err)
+	}
+}
+
+func TestVaultProviderNamespace(t *testing.T) {
+	server := vaultStub(t)
+	v := &VaultProvider{Address: server.URL, Token: "s.test-token", Client: server.Client()}
+	if _, err := resolveVault(t, v, "vault://team/data/db#password"); err == nil {
+		t.Error("read without the namespace header succeeded")
+	}
+	v.Namespace = "team"
+	if got, err := resolveVault(t, v, "vault://team/data/db#password"); err != nil || got != "hunter2" {
+		t.Errorf("with namespace: %q, %v; want hunter2", got, err)
+	}
+}
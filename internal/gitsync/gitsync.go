@@ -0,0 +1,156 @@
+// Package gitsync shares credential entries through a git repository.
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// File is the name of the shared entries file in the repository
+const File = "hosts.json"
+
+// Repo is a local working copy of the shared repository
+type Repo struct {
+	Dir    string
+	Remote string
+}
+
+// Open returns the working copy in dir, cloning remote into it first if
+// needed. A working copy of another repository is replaced by a fresh clone.
+// The remote can be any URL or path git understands, including a local bare
+// repository.
+func Open(dir, remote string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.New("git is required for sync but was not found in PATH")
+	}
+
+	r := &Repo{Dir: dir, Remote: remote}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		current, err := r.git("remote", "get-url", "origin")
+		if err != nil {
+			return nil, err
+		}
+		if current == remote {
+			return r, nil
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return nil, err
+	}
+	if _, err := run("", "clone", "--quiet", remote, dir); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Pull fetches the remote and returns its entries. An empty repository has none.
+func (r *Repo) Pull() ([]credential.SharedCredential, error) {
+	if _, err := r.git("fetch", "--quiet", "origin"); err != nil {
+		return nil, err
+	}
+
+	branch, err := r.branch()
+	if err != nil {
+		return nil, err
+	}
+	if !r.hasRemoteBranch(branch) {
+		return nil, nil
+	}
+
+	data, err := r.git("show", "origin/"+branch+":"+File)
+	if err != nil {
+		// The branch exists but nobody has shared entries yet
+		return nil, nil
+	}
+	var entries []credential.SharedCredential
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, fmt.Errorf("invalid %s in %s: %w", File, r.Remote, err)
+	}
+	return entries, nil
+}
+
+// Push commits entries on top of the fetched remote state and pushes them. It
+// reports whether there was anything to push.
+func (r *Repo) Push(entries []credential.SharedCredential, message string) (bool, error) {
+	branch, err := r.branch()
+	if err != nil {
+		return false, err
+	}
+	if r.hasRemoteBranch(branch) {
+		if _, err := r.git("checkout", "--quiet", "--force", "-B", branch, "origin/"+branch); err != nil {
+			return false, err
+		}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, File), append(data, '\n'), 0600); err != nil {
+		return false, err
+	}
+	if _, err := r.git("add", File); err != nil {
+		return false, err
+	}
+	if status, err := r.git("status", "--porcelain", "--", File); err != nil || status == "" {
+		return false, err
+	}
+
+	// Fall back to a fixed identity so sync works without a git user configured
+	commit := []string{"commit", "--quiet", "-m", message}
+	if email, _ := r.git("config", "user.email"); email == "" {
+		commit = append([]string{"-c", "user.name=ssh-cli", "-c", "user.email=ssh-cli@localhost"}, commit...)
+	}
+	if _, err := r.git(commit...); err != nil {
+		return false, err
+	}
+	if _, err := r.git("push", "--quiet", "origin", "HEAD:refs/heads/"+branch); err != nil {
+		return false, fmt.Errorf("%w\nthe remote changed during sync, run sync again", err)
+	}
+	return true, nil
+}
+
+// branch returns the branch the working copy tracks, which is the remote's
+// default branch after cloning
+func (r *Repo) branch() (string, error) {
+	return r.git("symbolic-ref", "--short", "HEAD")
+}
+
+func (r *Repo) hasRemoteBranch(branch string) bool {
+	_, err := r.git("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch)
+	return err == nil
+}
+
+func (r *Repo) git(args ...string) (string, error) {
+	return run(r.Dir, args...)
+}
+
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
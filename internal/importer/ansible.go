@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/ansible"
+)
+
+// Ansible reads a static Ansible inventory in INI or YAML form. Groups
+// become tags and inherited group variables are applied.
+type Ansible struct{}
+
+func (Ansible) Name() string { return "ansible" }
+
+func (Ansible) Detect(path string, data []byte) bool {
+	return bytes.Contains(data, []byte("ansible_host")) || bytes.Contains(data, []byte("ansible_user")) ||
+		bytes.Contains(data, []byte(":children]")) || bytes.HasPrefix(data, []byte("all:"))
+}
+
+// ansibleVars maps connection variables, including the pre-2.0 ansible_ssh_
+// spellings, to credential fields
+var ansibleVars = map[string]string{
+	"ansible_host":                 "host",
+	"ansible_ssh_host":             "host",
+	"ansible_port":                 "port",
+	"ansible_ssh_port":             "port",
+	"ansible_user":                 "username",
+	"ansible_ssh_user":             "username",
+	"ansible_ssh_private_key_file": "key_path",
+	"ansible_private_key_file":     "key_path",
+}
+
+func (Ansible) Import(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := ansible.Parse(decodeText(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	entries := make([]Entry, 0, len(inv.Hosts))
+	for _, host := range inv.Hosts {
+		entry := Entry{Source: fmt.Sprintf("%s host %s", filepath.Base(path), host.Name)}
+		entry.Map("inventory name", "name", host.Name)
+		entry.Map("inventory name", "host", host.Name)
+
+		keys := make([]string, 0, len(host.Vars))
+		for key := range host.Vars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := fmt.Sprint(host.Vars[key])
+			switch {
+			case ansibleVars[key] != "":
+				entry.Map(key, ansibleVars[key], value)
+			case key == "ansible_password" || key == "ansible_ssh_pass":
+				entry.Ignore(key)
+				entry.Warnings = append(entry.Warnings, key+" is not imported: add the password with 'ssh-cli ssh update'")
+			default:
+				entry.Ignore(key)
+			}
+		}
+		for _, group := range host.Groups {
+			entry.Tag("group", group, "")
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
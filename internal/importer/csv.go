@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// csvColumns maps the column names used by Termius, MobaXterm and similar
+// CSV exports to credential fields
+var csvColumns = map[string]string{
+	"label":         "name",
+	"name":          "name",
+	"alias":         "name",
+	"session name":  "name",
+	"hostname":      "host",
+	"hostname/ip":   "host",
+	"host":          "host",
+	"address":       "host",
+	"ip":            "host",
+	"server":        "host",
+	"remote host":   "host",
+	"port":          "port",
+	"username":      "username",
+	"user":          "username",
+	"login":         "username",
+	"group":         "tags.group",
+	"groups":        "tags.group",
+	"folder":        "tags.group",
+	"tags":          "tags",
+	"key":           "key_path",
+	"private key":   "key_path",
+	"identity file": "key_path",
+	"ssh key":       "key_path",
+	"protocol":      "protocol",
+	"type":          "protocol",
+}
+
+// CSV reads hosts from a CSV export with a header row, such as the ones
+// written by Termius and MobaXterm
+type CSV struct{}
+
+func (CSV) Name() string { return "csv" }
+
+func (CSV) Detect(path string, data []byte) bool {
+	return data != nil && strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+func (CSV) Import(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = decodeText(data)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	// Some exports use semicolons
+	if first, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		reader.Comma = ';'
+	}
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV %s: %w", path, err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("no hosts found in %s", path)
+	}
+
+	header := rows[0]
+	var hasHost bool
+	for _, column := range header {
+		hasHost = hasHost || csvColumns[strings.ToLower(strings.TrimSpace(column))] == "host"
+	}
+	if !hasHost {
+		return nil, fmt.Errorf("%s has no host column (expected one of Hostname, Host, Address, IP)", path)
+	}
+
+	var entries []Entry
+	for i, row := range rows[1:] {
+		entry := Entry{Source: fmt.Sprintf("%s line %d", filepath.Base(path), i+2)}
+		skip := false
+		for j, column := range header {
+			if j >= len(row) {
+				break
+			}
+			value := strings.TrimSpace(row[j])
+			field, known := csvColumns[strings.ToLower(strings.TrimSpace(column))]
+			switch {
+			case !known:
+				if value != "" {
+					entry.Ignore(column)
+				}
+			case field == "protocol":
+				skip = value != "" && !strings.EqualFold(value, "ssh")
+			case field == "host":
+				entry.MapTarget(column, value)
+			case field == "tags":
+				for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+					key, val, _ := strings.Cut(tag, "=")
+					entry.Tag(column, key, val)
+				}
+			default:
+				entry.Map(column, field, value)
+			}
+		}
+		if !skip && entry.Credential.Host != "" {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no SSH hosts found in %s", path)
+	}
+	return entries, nil
+}
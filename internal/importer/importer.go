@@ -0,0 +1,188 @@
+// Package importer reads sessions exported by other SSH clients.
+package importer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// Importer converts another client's export into credentials
+type Importer interface {
+	// Name is the format name used with --from
+	Name() string
+	// Detect reports whether the file at path looks like this format. data
+	// holds the start of the file, or is nil for directories.
+	Detect(path string, data []byte) bool
+	// Import reads every session at path
+	Import(path string) ([]Entry, error)
+}
+
+// Mapping records where a credential field came from
+type Mapping struct {
+	From  string
+	To    string
+	Value string
+}
+
+// Entry is one imported session with a report of how it was mapped
+type Entry struct {
+	Credential credential.SSHCredential
+	// Source identifies the session in the original export
+	Source   string
+	Mapped   []Mapping
+	Ignored  []string
+	Warnings []string
+}
+
+// Map sets a credential field from a source field and records the mapping.
+// Empty values are ignored.
+func (e *Entry) Map(from, to, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	c := &e.Credential
+	switch to {
+	case "name":
+		c.Name = value
+	case "host":
+		c.Host = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("invalid port %q in %s", value, from))
+			return
+		}
+		c.Port = port
+	case "username":
+		c.Username = value
+	case "key_path":
+		c.AuthType = credential.KeyFile
+		c.KeyPath = value
+	case "remote_command":
+		c.RemoteCommand = value
+	default:
+		if tag, ok := strings.CutPrefix(to, "tags."); ok {
+			e.Tag(from, tag, value)
+			return
+		}
+	}
+	e.Mapped = append(e.Mapped, Mapping{From: from, To: to, Value: value})
+}
+
+// MapTarget sets the host, and the user and port when given, from a
+// connection string such as user@host:port
+func (e *Entry) MapTarget(from, value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	t, err := credential.ParseTarget(value, "", 0)
+	if err != nil {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("%s: %v", from, err))
+		return
+	}
+	e.Map(from, "host", t.Host)
+	e.Map(from, "username", t.User)
+	if t.Port != 0 {
+		e.Map(from, "port", strconv.Itoa(t.Port))
+	}
+}
+
+// Tag adds a tag from a source field. Tags without a value are allowed.
+func (e *Entry) Tag(from, key, value string) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	if e.Credential.Tags == nil {
+		e.Credential.Tags = make(map[string]string)
+	}
+	e.Credential.Tags[key] = strings.TrimSpace(value)
+	e.Mapped = append(e.Mapped, Mapping{From: from, To: "tags." + key, Value: value})
+}
+
+// Ignore records source fields that have no equivalent
+func (e *Entry) Ignore(fields ...string) {
+	for _, field := range fields {
+		if strings.TrimSpace(field) != "" {
+			e.Ignored = append(e.Ignored, field)
+		}
+	}
+}
+
+// Report describes how the entry was mapped, on one line
+func (e Entry) Report() string {
+	parts := make([]string, len(e.Mapped))
+	for i, m := range e.Mapped {
+		parts[i] = m.From + " -> " + m.To
+	}
+	line := fmt.Sprintf("%s: %s", e.Source, strings.Join(parts, ", "))
+	if len(e.Ignored) > 0 {
+		ignored := append([]string(nil), e.Ignored...)
+		sort.Strings(ignored)
+		line += "; ignored " + strings.Join(ignored, ", ")
+	}
+	return line
+}
+
+// Importers lists the supported formats
+var Importers = []Importer{
+	PuTTY{},
+	Remmina{},
+	MobaXterm{},
+	CSV{},
+	Ansible{},
+	KnownHosts{},
+}
+
+// Names returns the supported format names
+func Names() []string {
+	names := make([]string, len(Importers))
+	for i, imp := range Importers {
+		names[i] = imp.Name()
+	}
+	return names
+}
+
+// Lookup returns the importer for a format name, or detects the format from
+// the file when name is auto
+func Lookup(name, path string) (Importer, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "termius" {
+		name = CSV{}.Name()
+	}
+	if name != "auto" {
+		for _, imp := range Importers {
+			if imp.Name() == name {
+				return imp, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown format %q: use auto, termius or one of %s", name, strings.Join(Names(), ", "))
+	}
+
+	var head []byte
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		head = decodeText(data)
+		if len(head) > 4096 {
+			head = head[:4096]
+		}
+	}
+	for _, imp := range Importers {
+		if imp.Detect(path, head) {
+			return imp, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot tell the format of %s: pass --from with one of %s", path, strings.Join(Names(), ", "))
+}
@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// utf16Text encodes s as UTF-16 with a byte order mark, as regedit exports it
+func utf16Text(order binary.ByteOrder, s string) []byte {
+	units := utf16.Encode([]rune(s))
+	data := make([]byte, 2+2*len(units))
+	order.PutUint16(data, 0xFEFF)
+	for i, unit := range units {
+		order.PutUint16(data[2+2*i:], unit)
+	}
+	return data
+}
+
+func TestDecodeText(t *testing.T) {
+	const text = "[Sessions\\caf\u00e9]\r\n\"HostName\"=\"h\u00f4te\"\r\n"
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"UTF-8", []byte(text)},
+		{"UTF-8 with BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...)},
+		{"UTF-16LE", utf16Text(binary.LittleEndian, text)},
+		{"UTF-16BE", utf16Text(binary.BigEndian, text)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(decodeText(tt.data)); got != text {
+				t.Errorf("decodeText = %q, want %q", got, text)
+			}
+		})
+	}
+}
+
+func TestParseINIUTF16(t *testing.T) {
+	reg := "Windows Registry Editor Version 5.00\r\n" +
+		"\r\n" +
+		"[HKEY_CURRENT_USER\\Software\\SimonTatham\\PuTTY\\Sessions\\web]\r\n" +
+		"\"HostName\"=\"10.0.0.1\"\r\n" +
+		"; comment\r\n" +
+		"\"PortNumber\"=dword:00000016\r\n" +
+		"\"HostName\"=\"10.0.0.2\"\r\n"
+
+	got := parseINI(utf16Text(binary.LittleEndian, reg))
+	want := []iniSection{
+		{Values: map[string]string{}},
+		{
+			Name: `HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\web`,
+			Keys: []string{`"HostName"`, `"PortNumber"`},
+			Values: map[string]string{
+				`"HostName"`:   `"10.0.0.2"`,
+				`"PortNumber"`: "dword:00000016",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseINI = %+v, want %+v", got, want)
+	}
+}
+
+func TestImport(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+
+	tests := []struct {
+		importer Importer
+		path     string
+		want     []credential.SSHCredential
+		// warnings are the warnings of all entries in order
+		warnings []string
+	}{
+		{
+			importer: PuTTY{},
+			path:     "putty.reg",
+			want: []credential.SSHCredential{
+				{Name: "prod web", Host: "10.0.0.1", Port: 22, Username: "deploy", AuthType: credential.KeyFile, KeyPath: `C:\Users\me\.ssh\id_ed25519`},
+				{Name: "legacy", Host: "10.0.0.2", Port: 2222, Username: "admin"},
+			},
+			warnings: []string{`C:\keys\legacy.ppk is a PuTTY key: convert it with 'puttygen key.ppk -O private-openssh -o key' and set it with ssh update --key`},
+		},
+		{
+			importer: Remmina{},
+			path:     "remmina",
+			want: []credential.SSHCredential{
+				{Name: "db", Host: "10.0.0.2", Username: "postgres"},
+				{
+					Name: "web", Host: "10.0.0.1", Port: 2222, Username: "deploy",
+					AuthType: credential.KeyFile, KeyPath: "/home/me/.ssh/id_ed25519",
+					Tags: map[string]string{"group": "prod"}, RemoteCommand: "tmux new -A -s main",
+				},
+			},
+			warnings: []string{"uses a password, which Remmina keeps in its own keyring: set it with ssh update --password-stdin"},
+		},
+		{
+			importer: MobaXterm{},
+			path:     "sessions.mxtsessions",
+			want: []credential.SSHCredential{
+				{Name: "web", Host: "10.0.0.1", Port: 22, Username: "root", AuthType: credential.KeyFile, KeyPath: "/home/me/.ssh/id_ed25519"},
+				{Name: "db", Host: "db.example.com", Port: 2222, Username: "postgres", Tags: map[string]string{"group": "prod/db"}},
+			},
+		},
+		{
+			importer: CSV{},
+			path:     "termius.csv",
+			want: []credential.SSHCredential{
+				{Name: "web", Host: "10.0.0.1", Port: 2222, Username: "deploy", Tags: map[string]string{"group": "prod", "env": "prod", "tier": "web"}},
+				{Name: "db", Host: "10.0.0.2", Username: "postgres"},
+			},
+		},
+		{
+			importer: Ansible{},
+			path:     "inventory.ini",
+			want: []credential.SSHCredential{
+				{Name: "web1", Host: "10.0.0.1", Username: "deploy", Tags: map[string]string{"prod": "", "web": ""}},
+				{Name: "web2", Host: "10.0.0.3", Username: "root", Tags: map[string]string{"prod": "", "web": ""}},
+				{
+					Name: "db1", Host: "10.0.0.2", Port: 2222, Username: "root",
+					AuthType: credential.KeyFile, KeyPath: "/home/me/.ssh/db",
+					Tags: map[string]string{"db": "", "prod": ""},
+				},
+			},
+			warnings: []string{"ansible_password is not imported: add the password with 'ssh-cli ssh update'"},
+		},
+		{
+			importer: KnownHosts{},
+			path:     "known_hosts",
+			want: []credential.SSHCredential{
+				{Name: "github.com", Host: "github.com", Port: 22},
+				{Name: "140.82.112.3", Host: "140.82.112.3", Port: 22},
+				{Name: "git.example.com-2222", Host: "git.example.com", Port: 2222},
+			},
+			warnings: []string{"1 hashed hosts were skipped"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.importer.Name(), func(t *testing.T) {
+			path := filepath.Join("testdata", tt.path)
+			if imp, err := Lookup("auto", path); err != nil || imp.Name() != tt.importer.Name() {
+				t.Errorf("Lookup(auto) = %v, %v, want %s", imp, err, tt.importer.Name())
+			}
+
+			entries, err := tt.importer.Import(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []credential.SSHCredential
+			var warnings []string
+			for _, entry := range entries {
+				got = append(got, entry.Credential)
+				warnings = append(warnings, entry.Warnings...)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("credentials = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestLookupTermius(t *testing.T) {
+	imp, err := Lookup("Termius", "")
+	if err != nil || imp.Name() != "csv" {
+		t.Errorf("Lookup(Termius) = %v, %v, want csv", imp, err)
+	}
+}
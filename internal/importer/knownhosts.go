@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// KnownHosts reads host names from an OpenSSH known_hosts file. It has no
+// users or keys, so those come from the configured defaults.
+type KnownHosts struct{}
+
+func (KnownHosts) Name() string { return "known_hosts" }
+
+func (KnownHosts) Detect(path string, data []byte) bool {
+	if strings.HasPrefix(filepath.Base(path), "known_hosts") {
+		return true
+	}
+	for _, keyType := range []string{" ssh-ed25519 ", " ssh-rsa ", " ecdsa-sha2-"} {
+		if bytes.Contains(data, []byte(keyType)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (KnownHosts) Import(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	var hashed int
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		// @cert-authority and @revoked lines describe keys, not hosts
+		if strings.HasPrefix(fields[0], "@") {
+			continue
+		}
+
+		for _, pattern := range strings.Split(fields[0], ",") {
+			switch {
+			case strings.HasPrefix(pattern, "|"):
+				hashed++
+				continue
+			case strings.ContainsAny(pattern, "*?!"):
+				continue
+			}
+			target, err := credential.ParseTarget(pattern, "", 22)
+			if err != nil {
+				continue
+			}
+			host, port := target.Host, strconv.Itoa(target.Port)
+			if seen[host+":"+port] {
+				continue
+			}
+			seen[host+":"+port] = true
+
+			entry := Entry{Source: fmt.Sprintf("%s line %d", filepath.Base(path), n)}
+			name := host
+			if port != "22" {
+				name = host + "-" + port
+			}
+			entry.Map("host pattern", "name", name)
+			entry.Map("host pattern", "host", host)
+			entry.Map("host pattern", "port", port)
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		if hashed > 0 {
+			return nil, fmt.Errorf("all %d hosts in %s are hashed (HashKnownHosts) and cannot be read back", hashed, path)
+		}
+		return nil, fmt.Errorf("no hosts found in %s", path)
+	}
+	if hashed > 0 {
+		entries[0].Warnings = append(entries[0].Warnings, fmt.Sprintf("%d hashed hosts were skipped", hashed))
+	}
+	return entries, nil
+}
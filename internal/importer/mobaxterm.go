@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// mobaSSHSession is the MobaXterm session type of SSH bookmarks
+const mobaSSHSession = "#109#"
+
+// MobaXterm reads SSH bookmarks from MobaXterm.ini or a .mxtsessions export
+type MobaXterm struct{}
+
+func (MobaXterm) Name() string { return "mobaxterm" }
+
+func (MobaXterm) Detect(path string, data []byte) bool {
+	return bytes.Contains(data, []byte("[Bookmarks")) || strings.EqualFold(filepath.Ext(path), ".mxtsessions")
+}
+
+func (MobaXterm) Import(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, section := range parseINI(data) {
+		if !strings.HasPrefix(section.Name, "Bookmarks") {
+			continue
+		}
+		folder := strings.ReplaceAll(section.Values["SubRep"], `\`, "/")
+		for _, name := range section.Keys {
+			value := section.Values[name]
+			fields, ok := strings.CutPrefix(value, mobaSSHSession)
+			if !ok {
+				continue
+			}
+			// Session settings are %-separated: flags, host, port, user, ...
+			settings := strings.Split(strings.SplitN(fields, "#", 2)[0], "%")
+			if len(settings) < 4 {
+				continue
+			}
+
+			entry := Entry{Source: fmt.Sprintf("MobaXterm bookmark %q", name)}
+			entry.Map("bookmark name", "name", strings.TrimSpace(strings.TrimSuffix(name, " ")))
+			entry.Map("remote host", "host", settings[1])
+			entry.Map("port", "port", settings[2])
+			entry.Map("username", "username", settings[3])
+			entry.Map("SubRep", "tags.group", folder)
+			if len(settings) > 14 && settings[14] != "" {
+				entry.Map("private key", "key_path", mobaPath(settings[14]))
+			}
+			entry.Ignore("terminal settings")
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no MobaXterm SSH bookmarks found in %s", path)
+	}
+	return entries, nil
+}
+
+// mobaPath expands the placeholders MobaXterm uses in paths
+func mobaPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "_ProfileDir_"); ok {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, filepath.FromSlash(strings.ReplaceAll(rest, `\`, "/")))
+		}
+	}
+	return path
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const puttySessionsKey = `\Software\SimonTatham\PuTTY\Sessions\`
+
+// PuTTY reads sessions from a registry export of
+// HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions
+type PuTTY struct{}
+
+func (PuTTY) Name() string { return "putty" }
+
+func (PuTTY) Detect(path string, data []byte) bool {
+	return bytes.Contains(data, []byte(`SimonTatham\PuTTY`)) ||
+		(data != nil && strings.EqualFold(filepath.Ext(path), ".reg"))
+}
+
+func (PuTTY) Import(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, section := range parseINI(data) {
+		_, encoded, ok := strings.Cut(section.Name, puttySessionsKey)
+		if !ok || encoded == "" || strings.Contains(encoded, `\`) {
+			continue
+		}
+		name, err := url.PathUnescape(encoded)
+		if err != nil {
+			name = encoded
+		}
+		if name == "Default Settings" {
+			continue
+		}
+
+		values := make(map[string]string, len(section.Values))
+		for _, key := range section.Keys {
+			values[strings.Trim(key, `"`)] = regValue(section.Values[key])
+		}
+		if protocol := values["Protocol"]; protocol != "" && protocol != "ssh" {
+			continue
+		}
+
+		entry := Entry{Source: fmt.Sprintf("PuTTY session %q", name)}
+		entry.Map("session name", "name", name)
+		entry.MapTarget("HostName", values["HostName"])
+		entry.Map("PortNumber", "port", values["PortNumber"])
+		entry.Map("UserName", "username", values["UserName"])
+		if key := values["PublicKeyFile"]; key != "" {
+			if strings.EqualFold(filepath.Ext(key), ".ppk") {
+				entry.Warnings = append(entry.Warnings, fmt.Sprintf("%s is a PuTTY key: convert it with 'puttygen key.ppk -O private-openssh -o key' and set it with ssh update --key", key))
+			} else {
+				entry.Map("PublicKeyFile", "key_path", key)
+			}
+		}
+		entry.Map("RemoteCommand", "remote_command", values["RemoteCommand"])
+		// PuTTY writes dozens of terminal settings; only report the ones that
+		// change how the connection behaves
+		for _, key := range []string{"ProxyHost", "PortForwardings", "AgentFwd", "X11Forward"} {
+			if v := values[key]; v != "" && v != "0" {
+				entry.Ignore(key)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no PuTTY SSH sessions found in %s", path)
+	}
+	return entries, nil
+}
+
+// regValue decodes a registry value: "string" or dword:0000hex
+func regValue(value string) string {
+	if hex, ok := strings.CutPrefix(value, "dword:"); ok {
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return ""
+		}
+		return strconv.FormatUint(n, 10)
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return strings.Trim(value, `"`)
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Remmina reads .remmina connection profiles, from one file or a directory
+// such as ~/.local/share/remmina
+type Remmina struct{}
+
+func (Remmina) Name() string { return "remmina" }
+
+func (Remmina) Detect(path string, data []byte) bool {
+	if data == nil {
+		matches, _ := filepath.Glob(filepath.Join(path, "*.remmina"))
+		return len(matches) > 0
+	}
+	return bytes.Contains(data, []byte("[remmina]")) || strings.EqualFold(filepath.Ext(path), ".remmina")
+}
+
+func (Remmina) Import(path string) ([]Entry, error) {
+	files := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.remmina")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, section := range parseINI(data) {
+			if section.Name != "remmina" {
+				continue
+			}
+			if entry, ok := remminaEntry(filepath.Base(file), section); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no Remmina SSH profiles found in %s", path)
+	}
+	return entries, nil
+}
+
+// remminaEntry maps an SSH or SFTP profile. Other protocols are skipped.
+func remminaEntry(file string, section iniSection) (Entry, bool) {
+	v := section.Values
+	switch strings.ToUpper(v["protocol"]) {
+	case "SSH", "SFTP":
+	default:
+		return Entry{}, false
+	}
+
+	entry := Entry{Source: fmt.Sprintf("Remmina profile %s", file)}
+	entry.Map("name", "name", v["name"])
+	entry.MapTarget("server", v["server"])
+	entry.Map("username", "username", v["username"])
+	entry.Map("ssh_username", "username", v["ssh_username"])
+	entry.Map("group", "tags.group", v["group"])
+
+	// ssh_auth: 0 password, 1 public key, 2 agent, 3 public key (automatic)
+	switch v["ssh_auth"] {
+	case "1", "3":
+		entry.Map("ssh_privatekey", "key_path", v["ssh_privatekey"])
+	case "0":
+		entry.Warnings = append(entry.Warnings, "uses a password, which Remmina keeps in its own keyring: set it with ssh update --password-stdin")
+	}
+	entry.Map("exec", "remote_command", v["exec"])
+
+	for _, key := range []string{"ssh_tunnel_enabled", "ssh_proxycommand", "ssh_forward_x11", "ssh_compression"} {
+		if value := v[key]; value != "" && value != "0" {
+			entry.Ignore(key)
+		}
+	}
+	return entry, true
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+)
+
+// decodeText returns data as UTF-8, converting the UTF-16 that Windows tools
+// such as regedit write and dropping byte order marks
+func decodeText(data []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		order = binary.BigEndian
+	default:
+		return bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+	}
+
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// iniSection is one [section] of an INI file, keeping the order of its keys
+type iniSection struct {
+	Name   string
+	Keys   []string
+	Values map[string]string
+}
+
+// parseINI reads an INI file. Keys before the first section go into a
+// section with an empty name; comment lines start with ; or #.
+func parseINI(data []byte) []iniSection {
+	sections := []iniSection{{Values: map[string]string{}}}
+	scanner := bufio.NewScanner(bytes.NewReader(decodeText(data)))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, ";"), strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			sections = append(sections, iniSection{Name: line[1 : len(line)-1], Values: map[string]string{}})
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		current := &sections[len(sections)-1]
+		if _, seen := current.Values[key]; !seen {
+			current.Keys = append(current.Keys, key)
+		}
+		current.Values[key] = strings.TrimSpace(value)
+	}
+	return sections
+}
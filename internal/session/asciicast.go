@@ -0,0 +1,233 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event types of the asciicast v2 format
+const (
+	EventOutput = "o"
+	EventResize = "r"
+)
+
+// Header is the first line of an asciicast v2 file
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is one line after the header: seconds since the start, the event
+// type and its data. It is encoded as a JSON array.
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Type, e.Data})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid event: expected 3 fields, got %d", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(fields[2], &e.Data)
+}
+
+// Recorder writes terminal output to an asciicast v2 file as it happens
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	buf     *bufio.Writer
+	start   time.Time
+	pending []byte
+}
+
+// Create starts a recording at path, readable by the owner only
+func Create(path string, header Header) (*Recorder, error) {
+	header.Version = 2
+	if header.Timestamp == 0 {
+		header.Timestamp = time.Now().Unix()
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{file: f, buf: bufio.NewWriter(f), start: time.Now()}
+	if err := r.writeLine(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write records output. A multi-byte character split across writes is held
+// back until it is complete, because event data must be valid UTF-8.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.pending, p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	r.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+	if err := r.event(EventOutput, string(data[:cut])); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Resize records a change of the terminal size
+func (r *Recorder) Resize(width, height int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.event(EventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Close flushes the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	if len(r.pending) > 0 {
+		err = r.event(EventOutput, string(r.pending))
+		r.pending = nil
+	}
+	return errors.Join(err, r.buf.Flush(), r.file.Close())
+}
+
+func (r *Recorder) event(kind, data string) error {
+	return r.writeLine(Event{Time: time.Since(r.start).Seconds(), Type: kind, Data: data})
+}
+
+func (r *Recorder) writeLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := r.buf.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	// Flush each event so an interrupted session still leaves a usable file
+	return r.buf.Flush()
+}
+
+// Cast is a recording read back from disk
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// Duration is the time of the last event
+func (c *Cast) Duration() time.Duration {
+	if len(c.Events) == 0 {
+		return 0
+	}
+	return time.Duration(c.Events[len(c.Events)-1].Time * float64(time.Second))
+}
+
+// Read loads an asciicast v2 recording. A truncated last line, as left by an
+// interrupted session, is ignored.
+func Read(path string) (*Cast, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	cast := &Cast{}
+	if err := json.Unmarshal(line, &cast.Header); err != nil {
+		return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+	}
+	if cast.Header.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version %d in %s", cast.Header.Version, path)
+	}
+
+	for n := 2; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var event Event
+			if jsonErr := json.Unmarshal(line, &event); jsonErr != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("invalid recording %s line %d: %w", path, n, jsonErr)
+			}
+			cast.Events = append(cast.Events, event)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cast, nil
+}
+
+// Play writes the recorded output to w with its original timing, scaled by
+// speed. Pauses longer than maxIdle are shortened to it when maxIdle is set.
+func Play(w io.Writer, cast *Cast, speed float64, maxIdle time.Duration) error {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	var last float64
+	for _, event := range cast.Events {
+		wait := time.Duration((event.Time - last) / speed * float64(time.Second))
+		if maxIdle > 0 && wait > maxIdle {
+			wait = maxIdle
+		}
+		last = event.Time
+		time.Sleep(wait)
+
+		if event.Type != EventOutput {
+			continue
+		}
+		if _, err := io.WriteString(w, event.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+//go:build !windows
+
+package session
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/term"
+)
+
+// TerminalSize returns the size of the terminal on stdin, or 80x24
+func TerminalSize() (width, height int) {
+	if width, height, err := term.GetSize(int(os.Stdin.Fd())); err == nil && width > 0 && height > 0 {
+		return width, height
+	}
+	return 80, 24
+}
+
+// Run starts cmd on a pseudo-terminal wired to the real one and records all
+// output it produces. It returns the error of cmd.Wait.
+func Run(cmd *exec.Cmd, rec *Recorder) error {
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		return err
+	}
+	// Closing the pty first unblocks a pending write of the input copy
+	stopInput := func() {}
+	defer func() {
+		ptmx.Close()
+		stopInput()
+	}()
+
+	// Follow size changes of the real terminal. The goroutine is waited for
+	// so that it never resizes the recording after Run returns.
+	resize := make(chan os.Signal, 1)
+	resized := make(chan struct{})
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer func() {
+		signal.Stop(resize)
+		close(resize)
+		<-resized
+	}()
+	go func() {
+		defer close(resized)
+		width, height := TerminalSize()
+		for range resize {
+			if err := pty.InheritSize(os.Stdin, ptmx); err != nil {
+				continue
+			}
+			if w, h := TerminalSize(); w != width || h != height {
+				width, height = w, h
+				rec.Resize(width, height)
+			}
+		}
+	}()
+	resize <- syscall.SIGWINCH
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		state, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return err
+		}
+		defer term.Restore(int(os.Stdin.Fd()), state)
+	}
+
+	if stopInput, err = copyInput(ptmx); err != nil {
+		return err
+	}
+
+	// Reading the pty fails with EIO once the session has ended
+	if _, err := io.Copy(io.MultiWriter(os.Stdout, rec), ptmx); err != nil && !errors.Is(err, syscall.EIO) {
+		cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
+
+// copyInput copies stdin to dst until the returned stop is called. stdin is
+// read through a non-blocking duplicate so that a pending read can be
+// cancelled instead of leaking the goroutine and swallowing the next key.
+func copyInput(dst io.Writer) (stop func(), err error) {
+	stdin := int(os.Stdin.Fd())
+	fd, err := syscall.Dup(stdin)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	in := os.NewFile(uintptr(fd), "stdin")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(dst, in)
+	}()
+	return func() {
+		// Input that cannot be polled, such as a regular file, ends by itself
+		if err := in.SetReadDeadline(time.Now()); err == nil {
+			<-done
+		}
+		in.Close()
+		// The duplicate shares its flags with stdin, which belongs to the shell
+		syscall.SetNonblock(stdin, false)
+	}, nil
+}
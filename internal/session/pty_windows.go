@@ -0,0 +1,16 @@
+package session
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// TerminalSize returns a default size; recording is not supported on Windows
+func TerminalSize() (width, height int) {
+	return 80, 24
+}
+
+// Run is not supported on Windows, which has no pseudo-terminals
+func Run(cmd *exec.Cmd, rec *Recorder) error {
+	return errors.New("session recording is not supported on Windows")
+}
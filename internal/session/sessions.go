@@ -0,0 +1,101 @@
+package session
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Extension is the file extension of recordings
+const Extension = ".cast"
+
+// Info describes a recording on disk
+type Info struct {
+	Name     string
+	Path     string
+	Title    string
+	Started  time.Time
+	Duration time.Duration
+	Size     int64
+}
+
+// NewPath returns the path for a new recording of a credential, creating the
+// directory if needed. A random suffix keeps recordings started in the same
+// second apart.
+func NewPath(dir, credential string, started time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, credential)
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s-%x%s", safe, started.Format("20060102-150405"), suffix, Extension)), nil
+}
+
+// List returns the recordings in dir, newest first. Files that cannot be read
+// are listed with what the file system knows about them.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []Info
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Extension {
+			continue
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		info := Info{
+			Name:    strings.TrimSuffix(entry.Name(), Extension),
+			Path:    filepath.Join(dir, entry.Name()),
+			Started: stat.ModTime(),
+			Size:    stat.Size(),
+		}
+		if cast, err := Read(info.Path); err == nil {
+			info.Title = cast.Header.Title
+			info.Duration = cast.Duration()
+			if cast.Header.Timestamp != 0 {
+				info.Started = time.Unix(cast.Header.Timestamp, 0)
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Started.After(infos[j].Started)
+	})
+	return infos, nil
+}
+
+// Find returns the path of a recording given by path or by its name in dir
+func Find(dir, nameOrPath string) (string, error) {
+	if _, err := os.Stat(nameOrPath); err == nil {
+		return nameOrPath, nil
+	}
+	path := filepath.Join(dir, strings.TrimSuffix(nameOrPath, Extension)+Extension)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("recording not found: %s", nameOrPath)
+	}
+	return path, nil
+}
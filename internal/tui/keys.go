@@ -0,0 +1,94 @@
+package tui
+
+import "unicode/utf8"
+
+type KeyType int
+
+const (
+	KeyRune KeyType = iota
+	KeyUp
+	KeyDown
+	KeyPgUp
+	KeyPgDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyCtrlC
+)
+
+// Key is a single decoded key press
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+var escapeSequences = map[string]KeyType{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1b[5~": KeyPgUp,
+	"\x1b[6~": KeyPgDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+}
+
+// ParseKeys decodes raw terminal input into key presses. Unknown escape
+// sequences are dropped.
+func ParseKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			if len(b) == 1 {
+				keys = append(keys, Key{Type: KeyEsc})
+				break
+			}
+			matched := false
+			for seq, kt := range escapeSequences {
+				if len(b) >= len(seq) && string(b[:len(seq)]) == seq {
+					keys = append(keys, Key{Type: kt})
+					b = b[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			if b[1] != '[' && b[1] != 'O' {
+				keys = append(keys, Key{Type: KeyEsc})
+				b = b[1:]
+				continue
+			}
+			// Skip an unknown CSI sequence up to its final byte
+			i := 2
+			for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+				i++
+			}
+			b = b[min(i+1, len(b)):]
+			continue
+		}
+
+		switch b[0] {
+		case '\r', '\n':
+			keys = append(keys, Key{Type: KeyEnter})
+		case 0x7f, 0x08:
+			keys = append(keys, Key{Type: KeyBackspace})
+		case 0x03:
+			keys = append(keys, Key{Type: KeyCtrlC})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r >= 0x20 {
+				keys = append(keys, Key{Type: KeyRune, Rune: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
@@ -0,0 +1,366 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+type Action int
+
+const (
+	ActionQuit Action = iota
+	ActionConnect
+	ActionEdit
+	ActionDelete
+)
+
+// Result is what the user chose to do when the UI exits
+type Result struct {
+	Action      Action
+	Credentials []credential.SSHCredential
+}
+
+// Model holds the UI state. It has no side effects, so it can be driven
+// directly from key presses.
+type Model struct {
+	creds     []credential.SSHCredential
+	filtered  []int
+	cursor    int
+	offset    int
+	query     string
+	searching bool
+	selected  map[string]bool
+	confirm   []credential.SSHCredential
+	status    string
+	clipboard string
+	enter     Action
+	only      Action
+	width     int
+	height    int
+}
+
+func NewModel(creds []credential.SSHCredential) *Model {
+	m := &Model{
+		selected: make(map[string]bool),
+		enter:    ActionConnect,
+		width:    80,
+		height:   24,
+	}
+	m.SetCredentials(creds)
+	return m
+}
+
+func credKey(cred credential.SSHCredential) string {
+	if cred.ID != "" {
+		return cred.ID
+	}
+	return cred.Name
+}
+
+// SetCredentials replaces the credential list, keeping the cursor and selection where possible
+func (m *Model) SetCredentials(creds []credential.SSHCredential) {
+	var current string
+	if cred, ok := m.Current(); ok {
+		current = credKey(cred)
+	}
+
+	m.creds = creds
+	present := make(map[string]bool, len(creds))
+	for _, cred := range creds {
+		present[credKey(cred)] = true
+	}
+	for key := range m.selected {
+		if !present[key] {
+			delete(m.selected, key)
+		}
+	}
+
+	m.applyFilter()
+	for i, idx := range m.filtered {
+		if credKey(m.creds[idx]) == current {
+			m.cursor = i
+		}
+	}
+	m.clampCursor()
+}
+
+// SetSize sets the terminal dimensions used for rendering
+func (m *Model) SetSize(width, height int) {
+	m.width, m.height = width, height
+	m.clampCursor()
+}
+
+// SetEnterAction sets what Enter does: connect (the default), edit, or
+// delete the selected credentials
+func (m *Model) SetEnterAction(action Action) {
+	m.enter = action
+}
+
+// SetOnlyAction turns the UI into a picker for one action: the keys for the
+// other actions are ignored. ActionQuit allows every action again.
+func (m *Model) SetOnlyAction(action Action) {
+	m.only = action
+}
+
+// allows reports whether an action may be started from the UI
+func (m *Model) allows(action Action) bool {
+	return m.only == ActionQuit || m.only == action
+}
+
+// SetStatus shows a message in the footer until the next key press
+func (m *Model) SetStatus(msg string) {
+	m.status = msg
+}
+
+// Current returns the credential under the cursor
+func (m *Model) Current() (credential.SSHCredential, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.filtered) {
+		return credential.SSHCredential{}, false
+	}
+	return m.creds[m.filtered[m.cursor]], true
+}
+
+// Selected returns the marked credentials, or the current one if nothing is marked
+func (m *Model) Selected() []credential.SSHCredential {
+	var selected []credential.SSHCredential
+	for _, cred := range m.creds {
+		if m.selected[credKey(cred)] {
+			selected = append(selected, cred)
+		}
+	}
+	if len(selected) == 0 {
+		if cred, ok := m.Current(); ok {
+			selected = append(selected, cred)
+		}
+	}
+	return selected
+}
+
+// TakeClipboard returns text waiting to be copied and clears it
+func (m *Model) TakeClipboard() string {
+	text := m.clipboard
+	m.clipboard = ""
+	return text
+}
+
+func (m *Model) listRows() int {
+	return max(m.height-2, 1)
+}
+
+func (m *Model) clampCursor() {
+	if m.cursor >= len(m.filtered) {
+		m.cursor = len(m.filtered) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	rows := m.listRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	if m.offset > max(len(m.filtered)-rows, 0) {
+		m.offset = max(len(m.filtered)-rows, 0)
+	}
+}
+
+func (m *Model) applyFilter() {
+	m.filtered = m.filtered[:0]
+	for i, cred := range m.creds {
+		if matches(cred, m.query) {
+			m.filtered = append(m.filtered, i)
+		}
+	}
+	m.clampCursor()
+}
+
+// matches reports whether every word of the query matches the credential. A word
+// of the form field:value only matches that field (name, host, user, auth, id, tag).
+func matches(cred credential.SSHCredential, query string) bool {
+	fields := map[string]string{
+		"name": cred.Name,
+		"host": cred.Host,
+		"user": cred.Username,
+		"auth": string(cred.AuthType),
+		"id":   cred.ID,
+		"tag":  credential.FormatTags(cred.Tags),
+	}
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if field, value, ok := strings.Cut(word, ":"); ok {
+			if v, known := fields[field]; known {
+				if !strings.Contains(strings.ToLower(v), value) {
+					return false
+				}
+				continue
+			}
+		}
+		haystack := strings.ToLower(strings.Join([]string{cred.Name, cred.Host, cred.Username, cred.ID}, " "))
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Update applies a key press and returns a result when the UI should exit
+func (m *Model) Update(k Key) *Result {
+	m.status = ""
+
+	if m.confirm != nil {
+		targets := m.confirm
+		m.confirm = nil
+		if k.Type == KeyRune && (k.Rune == 'y' || k.Rune == 'Y') {
+			for _, cred := range targets {
+				delete(m.selected, credKey(cred))
+			}
+			return &Result{Action: ActionDelete, Credentials: targets}
+		}
+		m.status = "Deletion cancelled"
+		return nil
+	}
+
+	if k.Type == KeyCtrlC {
+		return &Result{Action: ActionQuit}
+	}
+
+	if m.searching {
+		switch k.Type {
+		case KeyEnter:
+			m.searching = false
+		case KeyEsc:
+			m.searching = false
+			m.query = ""
+			m.applyFilter()
+		case KeyBackspace:
+			if r := []rune(m.query); len(r) > 0 {
+				m.query = string(r[:len(r)-1])
+				m.applyFilter()
+			}
+		case KeyRune:
+			m.query += string(k.Rune)
+			m.applyFilter()
+		case KeyUp, KeyDown, KeyPgUp, KeyPgDown, KeyHome, KeyEnd:
+			m.move(k)
+		}
+		return nil
+	}
+
+	switch k.Type {
+	case KeyUp, KeyDown, KeyPgUp, KeyPgDown, KeyHome, KeyEnd:
+		m.move(k)
+	case KeyEnter:
+		if m.enter == ActionDelete {
+			m.confirmDelete()
+			return nil
+		}
+		return m.act(m.enter)
+	case KeyEsc:
+		if m.query != "" {
+			m.query = ""
+			m.applyFilter()
+		}
+	case KeyRune:
+		switch k.Rune {
+		case 'q':
+			return &Result{Action: ActionQuit}
+		case 'k':
+			m.move(Key{Type: KeyUp})
+		case 'j':
+			m.move(Key{Type: KeyDown})
+		case 'g':
+			m.move(Key{Type: KeyHome})
+		case 'G':
+			m.move(Key{Type: KeyEnd})
+		case '/':
+			m.searching = true
+		case ' ':
+			if cred, ok := m.Current(); ok {
+				key := credKey(cred)
+				m.selected[key] = !m.selected[key]
+				if !m.selected[key] {
+					delete(m.selected, key)
+				}
+				m.move(Key{Type: KeyDown})
+			}
+		case 'a':
+			for _, idx := range m.filtered {
+				m.selected[credKey(m.creds[idx])] = true
+			}
+		case 'A':
+			m.selected = make(map[string]bool)
+		case 'c':
+			if m.allows(ActionConnect) {
+				return m.act(ActionConnect)
+			}
+		case 'e':
+			if m.allows(ActionEdit) {
+				return m.act(ActionEdit)
+			}
+		case 'd':
+			if m.allows(ActionDelete) {
+				m.confirmDelete()
+			}
+		case 'y':
+			if cred, ok := m.Current(); ok {
+				m.clipboard = SSHCommand(cred)
+				m.status = "Copied: " + m.clipboard
+			}
+		}
+	}
+	return nil
+}
+
+// confirmDelete asks before deleting the selected credentials
+func (m *Model) confirmDelete() {
+	if targets := m.Selected(); len(targets) > 0 {
+		m.confirm = targets
+		m.status = fmt.Sprintf("Delete %d credential(s)? (y/n)", len(targets))
+	}
+}
+
+func (m *Model) act(action Action) *Result {
+	cred, ok := m.Current()
+	if !ok {
+		return nil
+	}
+	return &Result{Action: action, Credentials: []credential.SSHCredential{cred}}
+}
+
+func (m *Model) move(k Key) {
+	switch k.Type {
+	case KeyUp:
+		m.cursor--
+	case KeyDown:
+		m.cursor++
+	case KeyPgUp:
+		m.cursor -= m.listRows()
+	case KeyPgDown:
+		m.cursor += m.listRows()
+	case KeyHome:
+		m.cursor = 0
+	case KeyEnd:
+		m.cursor = len(m.filtered) - 1
+	}
+	m.clampCursor()
+}
+
+// shellQuote quotes a string for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SSHCommand returns the plain ssh command line for a credential
+func SSHCommand(cred credential.SSHCredential) string {
+	command := fmt.Sprintf("ssh -p %d", cred.Port)
+	if cred.AuthType == credential.KeyFile && cred.KeyPath != "" {
+		command += " -i " + cred.KeyPath
+	}
+	if cred.RemoteCommand != "" {
+		return fmt.Sprintf("%s -t %s@%s %s", command, cred.Username, cred.Host, shellQuote(cred.RemoteCommand))
+	}
+	return fmt.Sprintf("%s %s@%s", command, cred.Username, cred.Host)
+}
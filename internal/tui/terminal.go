@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Terminal is the input and output the UI runs on. A fake implementation can
+// feed scripted key presses and capture the rendered screens.
+type Terminal interface {
+	io.ReadWriter
+	Size() (width, height int, err error)
+}
+
+// TTY is a Terminal backed by the process's controlling terminal
+type TTY struct {
+	in    *os.File
+	out   *os.File
+	state *term.State
+}
+
+func NewTTY(in, out *os.File) (*TTY, error) {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return nil, errors.New("the UI requires an interactive terminal")
+	}
+	return &TTY{in: in, out: out}, nil
+}
+
+func (t *TTY) Read(p []byte) (int, error)  { return t.in.Read(p) }
+func (t *TTY) Write(p []byte) (int, error) { return t.out.Write(p) }
+
+func (t *TTY) Size() (int, int, error) {
+	return term.GetSize(int(t.out.Fd()))
+}
+
+// Raw puts the terminal into raw mode until Restore is called
+func (t *TTY) Raw() error {
+	state, err := term.MakeRaw(int(t.in.Fd()))
+	if err != nil {
+		return err
+	}
+	t.state = state
+	return nil
+}
+
+// Restore returns the terminal to the mode it was in before Raw
+func (t *TTY) Restore() error {
+	if t.state == nil {
+		return nil
+	}
+	err := term.Restore(int(t.in.Fd()), t.state)
+	t.state = nil
+	return err
+}
+
+// Run draws the model and feeds it key presses until it produces a result.
+// End of input is treated as quitting.
+func Run(t Terminal, m *Model) (Result, error) {
+	// Use the alternate screen and hide the cursor while the UI is shown
+	if _, err := io.WriteString(t, "\x1b[?1049h\x1b[?25l"); err != nil {
+		return Result{}, err
+	}
+	defer io.WriteString(t, "\x1b[?25h\x1b[?1049l")
+
+	buf := make([]byte, 256)
+	for {
+		// Some terminals report no size; keep the last one then
+		if w, h, err := t.Size(); err == nil && w > 0 && h > 0 {
+			m.SetSize(w, h)
+		}
+		if _, err := io.WriteString(t, "\x1b[H\x1b[2J"+m.View()); err != nil {
+			return Result{}, err
+		}
+
+		n, err := t.Read(buf)
+		if n == 0 && err != nil {
+			if errors.Is(err, io.EOF) {
+				return Result{Action: ActionQuit}, nil
+			}
+			return Result{}, err
+		}
+
+		for _, k := range ParseKeys(buf[:n]) {
+			result := m.Update(k)
+			if text := m.TakeClipboard(); text != "" {
+				// OSC 52 asks the terminal emulator to put the text on the clipboard
+				io.WriteString(t, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte(text))+"\a")
+			}
+			if result != nil {
+				return *result, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+// fakeTerminal feeds scripted input, one chunk per Read, and records the output
+type fakeTerminal struct {
+	input  []string
+	output bytes.Buffer
+	width  int
+	height int
+}
+
+func newFakeTerminal(input ...string) *fakeTerminal {
+	return &fakeTerminal{input: input, width: 100, height: 20}
+}
+
+func (t *fakeTerminal) Read(p []byte) (int, error) {
+	if len(t.input) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, t.input[0])
+	t.input = t.input[1:]
+	return n, nil
+}
+
+func (t *fakeTerminal) Write(p []byte) (int, error) { return t.output.Write(p) }
+
+func (t *fakeTerminal) Size() (int, int, error) { return t.width, t.height, nil }
+
+func testCredentials() []credential.SSHCredential {
+	return []credential.SSHCredential{
+		{ID: "id-alpha", Name: "alpha", Host: "10.0.0.1", Port: 22, Username: "root", AuthType: credential.KeyFile, KeyPath: "/keys/alpha"},
+		{ID: "id-beta", Name: "beta", Host: "10.0.0.2", Port: 2222, Username: "deploy", AuthType: credential.Password, Tags: map[string]string{"env": "prod"}},
+		{ID: "id-gamma", Name: "gamma", Host: "db.internal", Port: 22, Username: "admin", AuthType: credential.KeyFile},
+	}
+}
+
+func names(creds []credential.SSHCredential) []string {
+	var out []string
+	for _, cred := range creds {
+		out = append(out, cred.Name)
+	}
+	return out
+}
+
+func run(t *testing.T, m *Model, input ...string) (Result, *fakeTerminal) {
+	t.Helper()
+	term := newFakeTerminal(input...)
+	result, err := Run(term, m)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return result, term
+}
+
+func TestRunNavigateAndConnect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  string
+	}{
+		{"enter connects to the first credential", []string{"\r"}, "alpha"},
+		{"j and k move", []string{"j", "j", "k", "\r"}, "beta"},
+		{"arrow keys move", []string{"\x1b[B", "\x1b[B", "\x1b[A", "\r"}, "beta"},
+		{"keys in one read", []string{"jj\r"}, "gamma"},
+		{"cursor stops at the end", []string{"jjjjj", "c"}, "gamma"},
+		{"home and end", []string{"G", "g", "\x1b[F", "\r"}, "gamma"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := run(t, NewModel(testCredentials()), tt.input...)
+			if result.Action != ActionConnect {
+				t.Fatalf("action = %v, want connect", result.Action)
+			}
+			if got := names(result.Credentials); !reflect.DeepEqual(got, []string{tt.want}) {
+				t.Errorf("credentials = %v, want [%s]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  string
+	}{
+		{"by name", []string{"/", "gam", "\r", "\r"}, "gamma"},
+		{"by host", []string{"/db.int\r\r"}, "gamma"},
+		{"by field", []string{"/", "user:deploy", "\r", "\r"}, "beta"},
+		{"by tag", []string{"/tag:env=prod\r\r"}, "beta"},
+		{"backspace widens the match", []string{"/", "gammx", "\x7f", "a", "\r", "\r"}, "gamma"},
+		{"escape clears the search", []string{"/", "gamma", "\x1b", "\r"}, "alpha"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := run(t, NewModel(testCredentials()), tt.input...)
+			if got := names(result.Credentials); result.Action != ActionConnect || !reflect.DeepEqual(got, []string{tt.want}) {
+				t.Errorf("result = %v %v, want connect [%s]", result.Action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSearchWithoutMatches(t *testing.T) {
+	result, term := run(t, NewModel(testCredentials()), "/", "nothing", "\r", "\r", "q")
+	if result.Action != ActionQuit {
+		t.Fatalf("action = %v, want quit: enter must not act without a credential", result.Action)
+	}
+	if !strings.Contains(term.output.String(), "0/3 credentials") {
+		t.Errorf("header does not show the empty filter:\n%q", term.output.String())
+	}
+}
+
+func TestRunQuit(t *testing.T) {
+	for _, input := range []string{"q", "\x03"} {
+		if result, _ := run(t, NewModel(testCredentials()), input); result.Action != ActionQuit {
+			t.Errorf("%q: action = %v, want quit", input, result.Action)
+		}
+	}
+	// End of input quits too
+	if result, _ := run(t, NewModel(testCredentials()), "j"); result.Action != ActionQuit {
+		t.Errorf("EOF: action = %v, want quit", result.Action)
+	}
+}
+
+func TestRunEdit(t *testing.T) {
+	result, _ := run(t, NewModel(testCredentials()), "j", "e")
+	if result.Action != ActionEdit || !reflect.DeepEqual(names(result.Credentials), []string{"beta"}) {
+		t.Errorf("result = %v %v, want edit [beta]", result.Action, names(result.Credentials))
+	}
+}
+
+func TestRunDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []string
+		action Action
+		want   []string
+	}{
+		{"current credential", []string{"j", "d", "y"}, ActionDelete, []string{"beta"}},
+		{"multi-select", []string{" ", " ", "d", "y"}, ActionDelete, []string{"alpha", "beta"}},
+		{"select all in a search", []string{"/10.0\r", "a", "d", "y"}, ActionDelete, []string{"alpha", "beta"}},
+		{"unselect", []string{" ", "k", " ", " ", "d", "y"}, ActionDelete, []string{"beta"}},
+		{"cancelled", []string{"d", "n", "q"}, ActionQuit, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := run(t, NewModel(testCredentials()), tt.input...)
+			if result.Action != tt.action || !reflect.DeepEqual(names(result.Credentials), tt.want) {
+				t.Errorf("result = %v %v, want %v %v", result.Action, names(result.Credentials), tt.action, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunEnterAction(t *testing.T) {
+	m := NewModel(testCredentials())
+	m.SetEnterAction(ActionEdit)
+	if result, _ := run(t, m, "j", "\r"); result.Action != ActionEdit || !reflect.DeepEqual(names(result.Credentials), []string{"beta"}) {
+		t.Errorf("result = %v %v, want edit [beta]", result.Action, names(result.Credentials))
+	}
+
+	m = NewModel(testCredentials())
+	m.SetEnterAction(ActionDelete)
+	if result, _ := run(t, m, " ", "\r", "y"); result.Action != ActionDelete || !reflect.DeepEqual(names(result.Credentials), []string{"alpha"}) {
+		t.Errorf("result = %v %v, want delete [alpha] after confirming", result.Action, names(result.Credentials))
+	}
+}
+
+func TestRunOnlyAction(t *testing.T) {
+	// An edit picker ignores the connect and delete keys
+	m := NewModel(testCredentials())
+	m.SetEnterAction(ActionEdit)
+	m.SetOnlyAction(ActionEdit)
+	result, term := run(t, m, "c", "d", "y", "j", "\r")
+	if result.Action != ActionEdit || !reflect.DeepEqual(names(result.Credentials), []string{"beta"}) {
+		t.Errorf("result = %v %v, want edit [beta]", result.Action, names(result.Credentials))
+	}
+	if out := term.output.String(); strings.Contains(out, "c: connect") || strings.Contains(out, "d: delete") {
+		t.Error("footer offers actions the picker ignores")
+	}
+
+	m = NewModel(testCredentials())
+	m.SetEnterAction(ActionDelete)
+	m.SetOnlyAction(ActionDelete)
+	if result, _ := run(t, m, "c", "e", "d", "y"); result.Action != ActionDelete || !reflect.DeepEqual(names(result.Credentials), []string{"alpha"}) {
+		t.Errorf("result = %v %v, want delete [alpha]", result.Action, names(result.Credentials))
+	}
+}
+
+func TestRunCopy(t *testing.T) {
+	_, term := run(t, NewModel(testCredentials()), "y", "q")
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("ssh -p 22 -i /keys/alpha root@10.0.0.1")) + "\a"
+	if !strings.Contains(term.output.String(), want) {
+		t.Errorf("output has no OSC 52 copy of the ssh command:\n%q", term.output.String())
+	}
+}
+
+func TestViewDetails(t *testing.T) {
+	m := NewModel(testCredentials())
+	m.SetSize(100, 20)
+	m.Update(Key{Type: KeyDown})
+	view := m.View()
+	for _, want := range []string{"3/3 credentials", "Name:      beta", "Host:      10.0.0.2:2222", "Tags:      env=prod", "ssh -p 2222 deploy@10.0.0.2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q", want)
+		}
+	}
+
+	// Narrow terminals only show the list
+	m.SetSize(40, 20)
+	if strings.Contains(m.View(), "Name:") {
+		t.Error("narrow view shows the detail pane")
+	}
+}
+
+func TestSetCredentialsKeepsCursor(t *testing.T) {
+	m := NewModel(testCredentials())
+	m.Update(Key{Type: KeyDown})
+	m.Update(Key{Type: KeyDown})
+	creds := testCredentials()
+	m.SetCredentials(creds[1:])
+	if cred, ok := m.Current(); !ok || cred.Name != "gamma" {
+		t.Errorf("current = %q, want gamma", cred.Name)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Key
+	}{
+		{"a", []Key{{Type: KeyRune, Rune: 'a'}}},
+		{"é", []Key{{Type: KeyRune, Rune: 'é'}}},
+		{"\x1b[A\x1bOB", []Key{{Type: KeyUp}, {Type: KeyDown}}},
+		{"\x1b[5~\x1b[6~", []Key{{Type: KeyPgUp}, {Type: KeyPgDown}}},
+		{"\r\n", []Key{{Type: KeyEnter}, {Type: KeyEnter}}},
+		{"\x1b", []Key{{Type: KeyEsc}}},
+		{"\x7f\x08", []Key{{Type: KeyBackspace}, {Type: KeyBackspace}}},
+		{"\x03", []Key{{Type: KeyCtrlC}}},
+	}
+	for _, tt := range tests {
+		if got := ParseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRunWithoutTerminalSize(t *testing.T) {
+	term := newFakeTerminal("q")
+	term.width, term.height = 0, 0
+	if _, err := Run(term, NewModel(testCredentials())); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(term.output.String(), "alpha") {
+		t.Error("nothing rendered when the terminal reports no size")
+	}
+}
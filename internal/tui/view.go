@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hemupadhyay26/ssh-cred-manager-cli/internal/credential"
+)
+
+const (
+	styleReverse = "\x1b[7m"
+	styleBold    = "\x1b[1m"
+	styleReset   = "\x1b[0m"
+)
+
+// fit truncates or pads s to exactly width runes
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		r := []rune(s)
+		if width == 1 {
+			return string(r[:1])
+		}
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+func details(cred credential.SSHCredential) []string {
+	lastUsed := "never"
+	if cred.LastUsedAt != nil {
+		lastUsed = cred.LastUsedAt.Format("2006-01-02 15:04")
+	}
+	lines := []string{
+		"Name:      " + cred.Name,
+		"ID:        " + cred.ID,
+		fmt.Sprintf("Host:      %s:%d", cred.Host, cred.Port),
+		"Username:  " + cred.Username,
+		"Auth Type: " + string(cred.AuthType),
+	}
+	if cred.AuthType == credential.KeyFile {
+		keyPath := cred.KeyPath
+		if keyPath == "" {
+			keyPath = "(ssh agent and config)"
+		}
+		lines = append(lines, "Key Path:  "+keyPath)
+	}
+	if len(cred.Tags) > 0 {
+		lines = append(lines, "Tags:      "+credential.FormatTags(cred.Tags))
+	}
+	lines = append(lines,
+		"Created:   "+cred.CreatedAt.Format("2006-01-02 15:04"),
+		"Updated:   "+cred.UpdatedAt.Format("2006-01-02 15:04"),
+		fmt.Sprintf("Last Used: %s (%d uses)", lastUsed, cred.UseCount),
+		"",
+		SSHCommand(cred),
+	)
+	return lines
+}
+
+// View renders the whole screen. Lines end in \r\n because the terminal is in raw mode.
+func (m *Model) View() string {
+	var b strings.Builder
+
+	header := fmt.Sprintf(" ssh-cli  %d/%d credentials", len(m.filtered), len(m.creds))
+	if len(m.selected) > 0 {
+		header += fmt.Sprintf("  %d selected", len(m.selected))
+	}
+	if m.searching || m.query != "" {
+		header += "  search: " + m.query
+		if m.searching {
+			header += "_"
+		}
+	}
+	b.WriteString(styleBold + fit(header, m.width) + styleReset + "\r\n")
+
+	listWidth := m.width
+	var detail []string
+	if m.width >= 60 {
+		listWidth = m.width * 2 / 5
+		if cred, ok := m.Current(); ok {
+			detail = details(cred)
+		}
+	}
+
+	rows := m.listRows()
+	for row := 0; row < rows; row++ {
+		line := ""
+		i := m.offset + row
+		if i < len(m.filtered) {
+			cred := m.creds[m.filtered[i]]
+			mark := "[ ]"
+			if m.selected[credKey(cred)] {
+				mark = "[x]"
+			}
+			line = fit(fmt.Sprintf(" %s %s (%s@%s)", mark, cred.Name, cred.Username, cred.Host), listWidth)
+			if i == m.cursor {
+				line = styleReverse + line + styleReset
+			}
+		} else {
+			line = fit("", listWidth)
+		}
+
+		if listWidth < m.width {
+			d := ""
+			if row < len(detail) {
+				d = detail[row]
+			}
+			line += "│ " + fit(d, m.width-listWidth-2)
+		}
+		b.WriteString(line + "\r\n")
+	}
+
+	footer := m.status
+	switch {
+	case footer != "":
+	case m.searching:
+		footer = " type to filter (field:value for name/host/user/auth/id/tag)  enter: done  esc: clear"
+	case len(m.filtered) == 0:
+		footer = " no matching credentials  /: search  esc: clear search  q: quit"
+	default:
+		enter := map[Action]string{ActionConnect: "connect", ActionEdit: "edit", ActionDelete: "delete"}[m.enter]
+		footer = " ↑↓/jk: move  /: search  space: select  a/A: select all/none  enter: " + enter
+		for _, key := range []struct {
+			action Action
+			help   string
+		}{{ActionConnect, "c: connect"}, {ActionEdit, "e: edit"}, {ActionDelete, "d: delete"}} {
+			if m.allows(key.action) {
+				footer += "  " + key.help
+			}
+		}
+		footer += "  y: copy  q: quit"
+	}
+	b.WriteString(styleReverse + fit(footer, m.width) + styleReset)
+
+	return b.String()
+}